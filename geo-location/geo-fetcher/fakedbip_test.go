@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+)
+
+// fakeDBIPResponse is the canned response of the fake db-ip server for a path.
+type fakeDBIPResponse struct {
+	status       int
+	headers      [][2]string
+	body         []byte
+	lastModified string        // answered with a 304 when the request has a matching If-Modified-Since
+	delay        time.Duration // responses slower than the call timeout are answered as a timeout
+}
+
+// fakeDBIP emulates the db-ip download endpoint by answering the http calls the plugin
+// dispatched to the db-ip cluster in the host emulator.
+type fakeDBIP struct {
+	t        *testing.T
+	host     proxytest.HostEmulator
+	routes   map[string]fakeDBIPResponse
+	requests [][][2]string // headers of all answered requests
+	answered int
+}
+
+// newFakeDBIP creates a fake db-ip server answering the http calls of the given host. Paths
+// without a route are answered with a 404.
+func newFakeDBIP(t *testing.T, host proxytest.HostEmulator) *fakeDBIP {
+	return &fakeDBIP{t: t, host: host, routes: map[string]fakeDBIPResponse{}}
+}
+
+// serve sets the response for the given path, replacing any previous one.
+func (f *fakeDBIP) serve(path string, response fakeDBIPResponse) {
+	f.routes[path] = response
+}
+
+// fetch ticks the plugin and answers the http calls it dispatched.
+func (f *fakeDBIP) fetch() {
+	f.t.Helper()
+	f.host.Tick()
+	if answered := f.flush(); answered != 1 {
+		f.t.Fatalf("expected a single http call on tick, got %d", answered)
+	}
+}
+
+// flush answers all pending http calls of the plugin and returns how many were answered.
+func (f *fakeDBIP) flush() int {
+	f.t.Helper()
+	callouts := f.host.GetCalloutAttributesFromContext(proxytest.PluginContextID)
+	pending := callouts[f.answered:]
+	f.answered = len(callouts)
+	for _, callout := range pending {
+		f.respond(callout)
+	}
+	return len(pending)
+}
+
+// lastRequest returns the headers of the last answered request.
+func (f *fakeDBIP) lastRequest() [][2]string {
+	f.t.Helper()
+	if len(f.requests) == 0 {
+		f.t.Fatal("expected a request to the fake db-ip server")
+	}
+	return f.requests[len(f.requests)-1]
+}
+
+func (f *fakeDBIP) respond(callout proxytest.HttpCalloutAttribute) {
+	f.t.Helper()
+	if callout.Upstream != "db-ip" {
+		f.t.Errorf("expected the call to the db-ip cluster, got %v", callout.Upstream)
+	}
+	if authority := getHeader(callout.Headers, ":authority"); authority != "download.db-ip.com" {
+		f.t.Errorf("expected the db-ip authority, got %q", authority)
+	}
+	f.requests = append(f.requests, callout.Headers)
+
+	response, ok := f.routes[getHeader(callout.Headers, ":path")]
+	switch {
+	case !ok:
+		response = fakeDBIPResponse{status: 404, body: []byte("not found")}
+	case response.delay >= geoDBFetchTimeout*time.Millisecond:
+		// Envoy calls back without headers nor body on a timeout
+		f.host.CallOnHttpCallResponse(callout.CalloutID, nil, nil, nil)
+		return
+	case response.lastModified != "" && getHeader(callout.Headers, "if-modified-since") == response.lastModified:
+		response = fakeDBIPResponse{status: 304, lastModified: response.lastModified}
+	}
+
+	headers := append([][2]string{{":status", strconv.Itoa(response.status)}}, response.headers...)
+	if response.lastModified != "" {
+		headers = append(headers, [2]string{"last-modified", response.lastModified})
+	}
+	f.host.CallOnHttpCallResponse(callout.CalloutID, headers, nil, response.body)
+}
+
+// mmdbResponse returns a 200 response with a gzipped MMDB database of the given type.
+func mmdbResponse(t *testing.T, databaseType, lastModified string) fakeDBIPResponse {
+	return fakeDBIPResponse{
+		status:       200,
+		headers:      [][2]string{{"content-type", "application/gzip"}},
+		body:         gzipBytes(t, string(buildMMDB(databaseType))),
+		lastModified: lastModified,
+	}
+}
+
+// truncated returns the response with only the first half of its body, like a download that
+// was cut off.
+func truncated(response fakeDBIPResponse) fakeDBIPResponse {
+	response.body = response.body[:len(response.body)/2]
+	return response
+}
+
+// buildMMDB builds a minimal MMDB database of the given type: an empty search tree and data
+// section followed by the metadata section, which is what geo-fetcher validates.
+func buildMMDB(databaseType string) []byte {
+	var db bytes.Buffer
+	db.Write(make([]byte, 16)) // data section separator
+	db.Write(mmdbMetadataMarker)
+	writeMMDBMap(&db, 8)
+	writeMMDBString(&db, "binary_format_major_version")
+	writeMMDBUint(&db, 5, 2)
+	writeMMDBString(&db, "binary_format_minor_version")
+	writeMMDBUint(&db, 5, 0)
+	writeMMDBString(&db, "build_epoch")
+	writeMMDBUint(&db, 9, 1696118400)
+	writeMMDBString(&db, "database_type")
+	writeMMDBString(&db, databaseType)
+	writeMMDBString(&db, "description")
+	writeMMDBMap(&db, 1)
+	writeMMDBString(&db, "en")
+	writeMMDBString(&db, "geo-fetcher test database")
+	writeMMDBString(&db, "ip_version")
+	writeMMDBUint(&db, 5, 6)
+	writeMMDBString(&db, "node_count")
+	writeMMDBUint(&db, 6, 0)
+	writeMMDBString(&db, "record_size")
+	writeMMDBUint(&db, 5, 24)
+	return db.Bytes()
+}
+
+// writeMMDBControl writes the control byte of an MMDB data field, for sizes below 29.
+func writeMMDBControl(db *bytes.Buffer, fieldType byte, size int) {
+	if fieldType > 7 {
+		db.Write([]byte{byte(size), fieldType - 7})
+		return
+	}
+	db.WriteByte(fieldType<<5 | byte(size))
+}
+
+func writeMMDBMap(db *bytes.Buffer, size int) {
+	writeMMDBControl(db, 7, size)
+}
+
+func writeMMDBString(db *bytes.Buffer, value string) {
+	writeMMDBControl(db, 2, len(value))
+	db.WriteString(value)
+}
+
+// writeMMDBUint writes an unsigned integer of the given field type (5 uint16, 6 uint32,
+// 9 uint64) without leading zero bytes.
+func writeMMDBUint(db *bytes.Buffer, fieldType byte, value uint64) {
+	encoded := binary.BigEndian.AppendUint64(nil, value)
+	encoded = bytes.TrimLeft(encoded, "\x00")
+	writeMMDBControl(db, fieldType, len(encoded))
+	db.Write(encoded)
+}
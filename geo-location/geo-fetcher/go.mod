@@ -4,6 +4,8 @@ go 1.19
 
 require github.com/tetratelabs/proxy-wasm-go-sdk v0.22.0
 
+require github.com/tetratelabs/wazero v1.0.0-rc.1 // indirect
+
 require (
 	geo-fetcher/utils v0.0.0
 	github.com/tidwall/gjson v1.17.0
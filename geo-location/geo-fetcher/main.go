@@ -20,8 +20,25 @@ const (
 	sharedDataPadByte    = byte(0) // Default padding byte is 0
 	sharedDataTargetSize = 8       // Desired length or its multiple for the byte slice
 	geoDBTaggerVMID      = "geo-tagger"
+	geoDBFetchTimeout    = 5000 // Timeout of the http call to db-ip in milliseconds
 )
 
+// MMDB data field types used to walk the metadata section of a database.
+const (
+	mmdbTypeExtended = 0
+	mmdbTypePointer  = 1
+	mmdbTypeMap      = 7
+	mmdbTypeArray    = 11
+	mmdbTypeBool     = 14
+)
+
+// mmdbMetadataMarker precedes the metadata section at the end of every MMDB database.
+var mmdbMetadataMarker = []byte("\xab\xcd\xefMaxMind.com")
+
+// setSharedData writes the shared data of the geo database. It is replaced in tests, as the
+// proxytest host emulator does not treat cas 0 as an unconditional write.
+var setSharedData = utils.SetSharedDataSafe
+
 // vmContext is the main context for the VM.
 type vmContext struct {
 	types.DefaultVMContext
@@ -40,8 +57,9 @@ type geoFetchConfig struct {
 // pluginContext represents the context for the plugin.
 type pluginContext struct {
 	types.DefaultPluginContext
-	config  geoFetchConfig
-	queueID uint32
+	config       geoFetchConfig
+	queueID      uint32
+	lastModified string // Last-Modified of the stored GeoDB, sent as If-Modified-Since
 }
 
 // NewPluginContext creates a new plugin context.
@@ -69,7 +87,7 @@ func (ctx *pluginContext) OnPluginStart(pluginConfigurationSize int) types.OnPlu
 	proxywasm.LogInfof("successfully parsed plugin configuration: %+v", config)
 
 	// Initialize the shared data with an empty value
-	err = utils.SetSharedDataSafe(geoDBKey, []byte{}, 0)
+	err = setSharedData(geoDBKey, []byte{}, 0)
 	if err != nil {
 		proxywasm.LogCriticalf("failed to initialize shared data: %v", err)
 		return types.OnPluginStartStatusFailed
@@ -124,14 +142,16 @@ func (ctx *pluginContext) fetchGeoDB() {
 		{":path", ctx.config.GeoDBURLPath},
 		{":scheme", "https"},
 	}
+	if ctx.lastModified != "" {
+		headers = append(headers, [2]string{"if-modified-since", ctx.lastModified})
+	}
 
 	callback := func(numHeaders, bodySize, numTrailers int) {
-		body, err := proxywasm.GetHttpCallResponseBody(0, bodySize)
-		if err != nil {
-			proxywasm.LogErrorf("failed to get response body: %v", err)
+		// Envoy calls back without headers when the call failed or timed out
+		if numHeaders == 0 {
+			proxywasm.LogErrorf("fetchGeoDB call failed or timed out after %dms", geoDBFetchTimeout)
 			return
 		}
-		proxywasm.LogInfof("fetchGeoDB response body size: %v", len(body))
 
 		headers, err := proxywasm.GetHttpCallResponseHeaders()
 		if err != nil {
@@ -140,6 +160,24 @@ func (ctx *pluginContext) fetchGeoDB() {
 		}
 		proxywasm.LogInfof("fetchGeoDB response headers: %+v", headers)
 
+		// Check the status first, only a 200 response carries a database worth reading
+		switch status := getHeader(headers, ":status"); status {
+		case "200":
+		case "304":
+			proxywasm.LogInfof("geo database not modified since %v", ctx.lastModified)
+			return
+		default:
+			proxywasm.LogErrorf("unexpected fetchGeoDB response status: %v", status)
+			return
+		}
+
+		body, err := proxywasm.GetHttpCallResponseBody(0, bodySize)
+		if err != nil {
+			proxywasm.LogErrorf("failed to get response body: %v", err)
+			return
+		}
+		proxywasm.LogInfof("fetchGeoDB response body size: %v", len(body))
+
 		// Decompress the gzipped data
 		gz, err := gzip.NewReader(bytes.NewReader(body))
 		if err != nil {
@@ -151,26 +189,125 @@ func (ctx *pluginContext) fetchGeoDB() {
 		extractedData, err := io.ReadAll(gz)
 		if err != nil {
 			proxywasm.LogErrorf("failed to read gzipped data: %v", err)
+			return
 		}
 		proxywasm.LogInfof("successfully read gzipped data of size: %v", len(extractedData))
 
+		if err := validateGeoDB(extractedData); err != nil {
+			proxywasm.LogErrorf("failed to validate geo database: %v", err)
+			return
+		}
+
 		if err := ctx.storeInSharedMemory(extractedData); err != nil {
 			proxywasm.LogErrorf("failed to store data in shared memory: %v", err)
 			return
 		}
 		proxywasm.LogInfof("successfully stored data of size %v in shared memory", len(extractedData))
+		ctx.lastModified = getHeader(headers, "last-modified")
 		ctx.notifyHTTPFilter()
 	}
 
-	if _, err := proxywasm.DispatchHttpCall("db-ip", headers, nil, nil, 5000, callback); err != nil {
+	if _, err := proxywasm.DispatchHttpCall("db-ip", headers, nil, nil, geoDBFetchTimeout, callback); err != nil {
 		proxywasm.LogCriticalf("dispatch httpcall failed: %v", err)
 	}
 }
 
-// storeInSharedMemory stores the fetched data in shared memory.
+// validateGeoDB checks that the data is an MMDB database with a complete metadata section, so a
+// truncated or unexpected download never replaces a working database in shared memory.
+func validateGeoDB(data []byte) error {
+	marker := bytes.LastIndex(data, mmdbMetadataMarker)
+	if marker < 0 {
+		return fmt.Errorf("no MMDB metadata marker found in %d bytes", len(data))
+	}
+	metadata := data[marker+len(mmdbMetadataMarker):]
+	if len(metadata) == 0 {
+		return fmt.Errorf("empty MMDB metadata section")
+	}
+	if metadata[0]>>5 != mmdbTypeMap {
+		return fmt.Errorf("MMDB metadata section is not a map")
+	}
+	if _, err := skipMMDBField(metadata, 0); err != nil {
+		return fmt.Errorf("invalid MMDB metadata section: %v", err)
+	}
+	return nil
+}
+
+// skipMMDBField returns the offset right after the MMDB data field at the given offset, or an
+// error when the field runs past the end of the data.
+// https://maxmind.github.io/MaxMind-DB/#output-data-section
+func skipMMDBField(data []byte, offset int) (int, error) {
+	if offset >= len(data) {
+		return 0, fmt.Errorf("data field at offset %d is truncated", offset)
+	}
+	control := data[offset]
+	offset++
+
+	fieldType := control >> 5
+	switch fieldType {
+	case mmdbTypeExtended:
+		if offset >= len(data) {
+			return 0, fmt.Errorf("extended type at offset %d is truncated", offset)
+		}
+		fieldType = 7 + data[offset]
+		offset++
+	case mmdbTypePointer:
+		offset += int(control>>3&0x3) + 1
+		if offset > len(data) {
+			return 0, fmt.Errorf("pointer at offset %d is truncated", offset)
+		}
+		return offset, nil
+	}
+
+	size := int(control & 0x1f)
+	if size >= 29 {
+		// sizes of 29 and more are followed by 1 to 3 bytes holding the rest of the size
+		n := size - 28
+		if offset+n > len(data) {
+			return 0, fmt.Errorf("size at offset %d is truncated", offset)
+		}
+		size = []int{29, 285, 65821}[n-1]
+		extra := 0
+		for _, b := range data[offset : offset+n] {
+			extra = extra<<8 | int(b)
+		}
+		size += extra
+		offset += n
+	}
+
+	var err error
+	switch fieldType {
+	case mmdbTypeMap:
+		for i := 0; i < 2*size && err == nil; i++ {
+			offset, err = skipMMDBField(data, offset)
+		}
+	case mmdbTypeArray:
+		for i := 0; i < size && err == nil; i++ {
+			offset, err = skipMMDBField(data, offset)
+		}
+	case mmdbTypeBool:
+		// the value of a boolean is stored in its size
+	default:
+		offset += size
+		if offset > len(data) {
+			err = fmt.Errorf("data field ending at offset %d is truncated", offset)
+		}
+	}
+	return offset, err
+}
+
+// getHeader returns the value of the given header, or an empty string if it is missing.
+func getHeader(headers [][2]string, name string) string {
+	for _, header := range headers {
+		if header[0] == name {
+			return header[1]
+		}
+	}
+	return ""
+}
+
+// storeInSharedMemory stores the fetched data in shared memory, overwriting the current value.
 func (ctx *pluginContext) storeInSharedMemory(data []byte) error {
-	err := utils.SetSharedDataSafe(geoDBKey, data, 0)
-	if err != nil {
+	if err := setSharedData(geoDBKey, data, 0); err != nil {
 		return fmt.Errorf("failed to set shared data: %v", err)
 	}
 	return nil
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+	"time"
+
+	"geo-fetcher/utils"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// startTestPlugin creates a host emulator with the given plugin configuration and starts the
+// plugin, failing the test if the plugin does not start.
+func startTestPlugin(t *testing.T, config string) (proxytest.HostEmulator, func()) {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&vmContext{}).WithPluginConfiguration([]byte(config))
+	host, resetHost := proxytest.NewHostEmulator(opt)
+	setSharedData = setSharedDataUnconditionally
+	reset := func() {
+		setSharedData = utils.SetSharedDataSafe
+		resetHost()
+	}
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		reset()
+		t.Fatalf("expected the plugin to start, got %v", status)
+	}
+	return host, reset
+}
+
+// setSharedDataUnconditionally writes shared data like envoy does for cas 0, by passing the
+// current cas of the key. The host emulator compares cas 0 with the cas of an existing key.
+func setSharedDataUnconditionally(key string, data []byte, cas uint32) error {
+	if cas == 0 {
+		if _, current, err := proxywasm.GetSharedData(key); err == nil {
+			cas = current
+		}
+	}
+	return utils.SetSharedDataSafe(key, data, cas)
+}
+
+// gzipBytes compresses the given data, failing the test on errors.
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseGeoServiceConfiguration(t *testing.T) {
+	config, err := parseGeoServiceConfiguration([]byte(`{"geo_db_url_path": "/free/dbip-city-lite-2023-10.mmdb.gz", "polling_interval": 60000}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.GeoDBURLPath != "/free/dbip-city-lite-2023-10.mmdb.gz" || config.PollingInterval != 60000 {
+		t.Errorf("unexpected configuration %+v", config)
+	}
+
+	config, err = parseGeoServiceConfiguration(nil)
+	if err != nil || config != (geoFetchConfig{}) {
+		t.Errorf("expected an empty configuration without error, got %+v (%v)", config, err)
+	}
+
+	if _, err := parseGeoServiceConfiguration([]byte(`{"polling_interval":`)); err == nil {
+		t.Error("expected an error for an invalid json configuration")
+	}
+}
+
+func TestOnPluginStart(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"geo_db_url_path": "/geo.mmdb.gz", "polling_interval": 30000}`)
+	defer reset()
+
+	if period := host.GetTickPeriod(); period != 30000 {
+		t.Errorf("expected a tick period of 30000ms, got %v", period)
+	}
+
+	data, _, err := utils.GetSharedDataSafe(geoDBKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected the shared data to be initialized empty, got %d bytes", len(data))
+	}
+}
+
+func TestOnPluginStartInvalidConfig(t *testing.T) {
+	opt := proxytest.NewEmulatorOption().WithVMContext(&vmContext{}).WithPluginConfiguration([]byte(`{"polling_interval":`))
+	host, reset := proxytest.NewHostEmulator(opt)
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusFailed {
+		t.Errorf("expected the plugin to fail on an invalid configuration, got %v", status)
+	}
+}
+
+// sharedGeoDB returns the geo database stored in shared data.
+func sharedGeoDB(t *testing.T) []byte {
+	t.Helper()
+	data, _, err := utils.GetSharedDataSafe(geoDBKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// updateNotifications returns the number of update notifications on the shared queue.
+func updateNotifications(t *testing.T, host proxytest.HostEmulator) int {
+	t.Helper()
+	queueID, err := proxywasm.RegisterSharedQueue(geoDBUpdateQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host.GetQueueSize(queueID)
+}
+
+func TestFetchGeoDB(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"geo_db_url_path": "/geo.mmdb.gz", "polling_interval": 30000}`)
+	defer reset()
+
+	dbip := newFakeDBIP(t, host)
+	dbip.serve("/geo.mmdb.gz", mmdbResponse(t, "DBIP-Country-Lite", "Sun, 01 Oct 2023 00:00:00 GMT"))
+	dbip.fetch()
+
+	if path := getHeader(dbip.lastRequest(), ":path"); path != "/geo.mmdb.gz" {
+		t.Errorf("expected the configured path, got %q", path)
+	}
+	if data := sharedGeoDB(t); !bytes.Equal(data, buildMMDB("DBIP-Country-Lite")) {
+		t.Errorf("expected the decompressed database in shared data, got %q", data)
+	}
+	if size := updateNotifications(t, host); size != 1 {
+		t.Errorf("expected an update notification on the shared queue, got %d messages", size)
+	}
+}
+
+func TestFetchGeoDBNotModified(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"geo_db_url_path": "/geo.mmdb.gz", "polling_interval": 30000}`)
+	defer reset()
+
+	dbip := newFakeDBIP(t, host)
+	dbip.serve("/geo.mmdb.gz", mmdbResponse(t, "DBIP-Country-Lite", "Sun, 01 Oct 2023 00:00:00 GMT"))
+	dbip.fetch()
+	if since := getHeader(dbip.lastRequest(), "if-modified-since"); since != "" {
+		t.Errorf("expected no If-Modified-Since without a stored database, got %q", since)
+	}
+
+	dbip.fetch()
+	if since := getHeader(dbip.lastRequest(), "if-modified-since"); since != "Sun, 01 Oct 2023 00:00:00 GMT" {
+		t.Errorf("expected the Last-Modified of the stored database as If-Modified-Since, got %q", since)
+	}
+	if size := updateNotifications(t, host); size != 1 {
+		t.Errorf("expected no update notification for a not modified database, got %d messages", size)
+	}
+
+	dbip.serve("/geo.mmdb.gz", mmdbResponse(t, "DBIP-City-Lite", "Wed, 01 Nov 2023 00:00:00 GMT"))
+	dbip.fetch()
+	if data := sharedGeoDB(t); !bytes.Equal(data, buildMMDB("DBIP-City-Lite")) {
+		t.Errorf("expected the modified database in shared data, got %q", data)
+	}
+	if size := updateNotifications(t, host); size != 2 {
+		t.Errorf("expected an update notification for the modified database, got %d messages", size)
+	}
+}
+
+// A failed download must leave the previously fetched database in place and not notify the
+// geo-tagger.
+func TestFetchGeoDBFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		response fakeDBIPResponse
+	}{
+		{"not found", fakeDBIPResponse{status: 404, body: []byte("not found")}},
+		{"server error", fakeDBIPResponse{status: 503}},
+		{"timeout", fakeDBIPResponse{status: 200, body: gzipBytes(t, string(buildMMDB("DBIP-City-Lite"))), delay: 10 * time.Second}},
+		{"truncated", truncated(mmdbResponse(t, "DBIP-City-Lite", ""))},
+		{"invalid gzip", fakeDBIPResponse{status: 200, body: []byte("not gzipped")}},
+		{"not an mmdb", fakeDBIPResponse{status: 200, body: gzipBytes(t, "<html>maintenance</html>")}},
+		// the last byte of the MMDB is the record_size value
+		{"truncated mmdb", fakeDBIPResponse{status: 200, body: gzipBytes(t, strings.TrimSuffix(string(buildMMDB("DBIP-City-Lite")), "\x18"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, reset := startTestPlugin(t, `{"geo_db_url_path": "/geo.mmdb.gz", "polling_interval": 30000}`)
+			defer reset()
+
+			dbip := newFakeDBIP(t, host)
+			dbip.serve("/geo.mmdb.gz", mmdbResponse(t, "DBIP-Country-Lite", ""))
+			dbip.fetch()
+
+			dbip.serve("/geo.mmdb.gz", tt.response)
+			dbip.fetch()
+			if logs := host.GetErrorLogs(); len(logs) == 0 {
+				t.Error("expected an error to be logged for the failed download")
+			}
+			if data := sharedGeoDB(t); !bytes.Equal(data, buildMMDB("DBIP-Country-Lite")) {
+				t.Errorf("expected the previous database to be kept in shared data, got %q", data)
+			}
+			if size := updateNotifications(t, host); size != 1 {
+				t.Errorf("expected no update notification for the failed download, got %d messages", size)
+			}
+		})
+	}
+}
+
+func TestFetchGeoDBUnknownPath(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"geo_db_url_path": "/missing.mmdb.gz", "polling_interval": 30000}`)
+	defer reset()
+
+	dbip := newFakeDBIP(t, host)
+	dbip.fetch()
+	if data := sharedGeoDB(t); len(data) != 0 {
+		t.Errorf("expected the shared data to be left empty, got %d bytes", len(data))
+	}
+	if size := updateNotifications(t, host); size != 0 {
+		t.Errorf("expected no update notification, got %d messages", size)
+	}
+	for _, log := range host.GetInfoLogs() {
+		if strings.HasPrefix(log, "fetchGeoDB response body size") {
+			t.Errorf("expected the body of a 404 response not to be read, got %q", log)
+		}
+	}
+}
+
+func TestValidateGeoDB(t *testing.T) {
+	valid := buildMMDB("DBIP-Country-Lite")
+	metadata := bytes.LastIndex(valid, mmdbMetadataMarker) + len(mmdbMetadataMarker)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"valid", valid, false},
+		{"truncated in the data section", valid[:8], true},
+		{"truncated in the metadata section", valid[:metadata+40], true},
+		{"truncated by one byte", valid[:len(valid)-1], true},
+		{"empty metadata section", valid[:metadata], true},
+		{"metadata not a map", append(valid[:metadata:metadata], 0x42, 'e', 'n'), true},
+		{"not an mmdb", []byte("<html>maintenance</html>"), true},
+		{"empty", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateGeoDB(tt.data); (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
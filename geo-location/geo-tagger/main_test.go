@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+
+	"geo-tagger/utils"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// newTestHost creates a host emulator for the plugin. The geo database is stored in shared
+// data beforehand when given, as geo-fetcher does on its own plugin start.
+func newTestHost(t *testing.T, geoDB []byte) (proxytest.HostEmulator, func()) {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&vmContext{})
+	host, reset := proxytest.NewHostEmulator(opt)
+	if geoDB != nil {
+		if err := utils.SetSharedDataSafe(geoDBKey, geoDB, 0); err != nil {
+			reset()
+			t.Fatal(err)
+		}
+	}
+	return host, reset
+}
+
+// containsLog returns true if the given log line was emitted.
+func containsLog(logs []string, line string) bool {
+	for _, log := range logs {
+		if log == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOnPluginStart(t *testing.T) {
+	host, reset := newTestHost(t, []byte{})
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		t.Fatalf("expected the plugin to start, got %v", status)
+	}
+	if logs := host.GetInfoLogs(); !containsLog(logs, "successfully registered to shared queue: "+geoDBUpdateQueue) {
+		t.Errorf("expected the plugin to register to the shared queue, got %v", logs)
+	}
+}
+
+func TestOnPluginStartWithoutSharedData(t *testing.T) {
+	host, reset := newTestHost(t, nil)
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusFailed {
+		t.Errorf("expected the plugin to fail without shared data, got %v", status)
+	}
+}
+
+func TestOnQueueReady(t *testing.T) {
+	host, reset := newTestHost(t, []byte{})
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		t.Fatalf("expected the plugin to start, got %v", status)
+	}
+
+	// Act as geo-fetcher: store a new database and notify the tagger through the queue
+	if err := utils.SetSharedDataSafe(geoDBKey, []byte("geo database"), 1); err != nil {
+		t.Fatal(err)
+	}
+	queueID, err := proxywasm.RegisterSharedQueue(geoDBUpdateQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proxywasm.EnqueueSharedQueue(queueID, []byte("update_available")); err != nil {
+		t.Fatal(err)
+	}
+
+	if logs := host.GetInfoLogs(); !containsLog(logs, "successfully read updated shared data of size: 12") {
+		t.Errorf("expected the updated database to be read, got %v", logs)
+	}
+	if size := host.GetQueueSize(queueID); size != 0 {
+		t.Errorf("expected the queue to be drained, got %d messages", size)
+	}
+}
+
+func TestOnQueueReadyIgnoresUnknownMessages(t *testing.T) {
+	host, reset := newTestHost(t, []byte{})
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		t.Fatalf("expected the plugin to start, got %v", status)
+	}
+
+	queueID, err := proxywasm.RegisterSharedQueue(geoDBUpdateQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proxywasm.EnqueueSharedQueue(queueID, []byte("unknown")); err != nil {
+		t.Fatal(err)
+	}
+
+	if logs := host.GetInfoLogs(); containsLog(logs, "successfully read updated shared data of size: 0") {
+		t.Errorf("expected unknown messages not to trigger a database read, got %v", logs)
+	}
+	if size := host.GetQueueSize(queueID); size != 0 {
+		t.Errorf("expected the queue to be drained, got %d messages", size)
+	}
+}
@@ -7,6 +7,7 @@ require github.com/tetratelabs/proxy-wasm-go-sdk v0.22.0
 require github.com/tidwall/gjson v1.17.0
 
 require (
+	github.com/tetratelabs/wazero v1.0.0-rc.1 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
 )
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"header-propagator/properties"
 	"header-propagator/utils"
 	"strings"
@@ -15,11 +16,25 @@ const (
 	Outbound = "OUTBOUND"
 )
 
+// Connection properties the network filter can read the correlation value from
+const (
+	SourceSni      = "sni"
+	SourceProxyTlv = "proxyTlv"
+)
+
+// Content-type prefix of gRPC requests and responses (application/grpc, application/grpc+proto, ...)
+const GrpcContentType = "application/grpc"
+
+// Dynamic metadata namespace the proxy protocol listener filter stores TLV values in
+const defaultTlvNamespace = "envoy.filters.listener.proxy_protocol"
+
 type pluginConfig struct {
-	CorrelationHeader   string            `json:"correlationHeader"`
-	PropagationHeader   propagationHeader `json:"propagationHeader"`
-	RequestPropagation  bool              `json:"requestPropagation"`
-	ResponsePropagation bool              `json:"responsePropagation"`
+	CorrelationHeader      string            `json:"correlationHeader"`
+	CorrelationFilterState string            `json:"correlationFilterState"`
+	PropagationHeader      propagationHeader `json:"propagationHeader"`
+	RequestPropagation     bool              `json:"requestPropagation"`
+	ResponsePropagation    bool              `json:"responsePropagation"`
+	NetworkFilter          networkFilter     `json:"networkFilter"`
 }
 
 type propagationHeader struct {
@@ -27,6 +42,14 @@ type propagationHeader struct {
 	Default string `json:"default"`
 }
 
+// Network filter mode, enabled by setting a source. The correlation value read from the
+// connection is stored in the correlationFilterState filter state for the http filters
+type networkFilter struct {
+	Source       string `json:"source"`
+	TlvNamespace string `json:"tlvNamespace"`
+	TlvKey       string `json:"tlvKey"`
+}
+
 func main() {
 	proxywasm.SetVMContext(&vmContext{})
 }
@@ -56,48 +79,99 @@ func (p *pluginContext) OnPluginStart(pluginConfigurationSize int) types.OnPlugi
 
 	jsonData := gjson.ParseBytes(configData)
 	p.config = pluginConfig{
-		RequestPropagation:  jsonData.Get("requestPropagation").Bool(),
-		ResponsePropagation: jsonData.Get("responsePropagation").Bool(),
-		CorrelationHeader:   jsonData.Get("correlationHeader").String(),
+		RequestPropagation:     jsonData.Get("requestPropagation").Bool(),
+		ResponsePropagation:    jsonData.Get("responsePropagation").Bool(),
+		CorrelationHeader:      jsonData.Get("correlationHeader").String(),
+		CorrelationFilterState: jsonData.Get("correlationFilterState").String(),
 		PropagationHeader: propagationHeader{
 			Name:    jsonData.Get("propagationHeader.name").String(),
 			Default: jsonData.Get("propagationHeader.default").String(),
 		},
+		NetworkFilter: networkFilter{
+			Source:       jsonData.Get("networkFilter.source").String(),
+			TlvNamespace: jsonData.Get("networkFilter.tlvNamespace").String(),
+			TlvKey:       jsonData.Get("networkFilter.tlvKey").String(),
+		},
+	}
+
+	if p.config.NetworkFilter.TlvNamespace == "" {
+		p.config.NetworkFilter.TlvNamespace = defaultTlvNamespace
+	}
+	if err := validateNetworkFilter(p.config); err != nil {
+		proxywasm.LogErrorf("Invalid network filter configuration: %v", err)
+		return types.OnPluginStartStatusFailed
 	}
 
 	return types.OnPluginStartStatusOK
 }
 
+func validateNetworkFilter(config pluginConfig) error {
+	switch config.NetworkFilter.Source {
+	case "":
+		return nil
+	case SourceSni:
+	case SourceProxyTlv:
+		if config.NetworkFilter.TlvKey == "" {
+			return fmt.Errorf("source %v requires a tlvKey", SourceProxyTlv)
+		}
+	default:
+		return fmt.Errorf("unknown source %q", config.NetworkFilter.Source)
+	}
+	if config.CorrelationFilterState == "" {
+		return fmt.Errorf("network filter requires a correlationFilterState")
+	}
+	return nil
+}
+
+// The sdk prefers http contexts over tcp contexts, so no http context is created in network
+// filter mode
 func (p *pluginContext) NewHttpContext(contextID uint32) types.HttpContext {
+	if p.config.NetworkFilter.Source != "" {
+		return nil
+	}
 	return &httpContext{
-		contextID:           contextID,
-		correlationHeader:   p.config.CorrelationHeader,
-		propagationHeader:   p.config.PropagationHeader,
-		requestPropagation:  p.config.RequestPropagation,
-		responsePropagation: p.config.ResponsePropagation,
+		contextID:              contextID,
+		correlationHeader:      p.config.CorrelationHeader,
+		correlationFilterState: p.config.CorrelationFilterState,
+		propagationHeader:      p.config.PropagationHeader,
+		requestPropagation:     p.config.RequestPropagation,
+		responsePropagation:    p.config.ResponsePropagation,
+		snapshot:               properties.NewSnapshot(),
 	}
 }
 
 type httpContext struct {
 	types.DefaultHttpContext
-	contextID           uint32
-	correlationHeader   string
-	propagationHeader   propagationHeader
-	requestPropagation  bool
-	responsePropagation bool
+	contextID              uint32
+	correlationHeader      string
+	correlationFilterState string
+	propagationHeader      propagationHeader
+	requestPropagation     bool
+	responsePropagation    bool
+	snapshot               *properties.Snapshot
+	grpcPending            bool   // gRPC response that gets the propagation header in its trailers too
+	grpcCorrelation        string // correlation value seen in the gRPC response headers
 }
 
 func (ctx *httpContext) OnHttpRequestHeaders(numHeaders int, endOfStream bool) types.Action {
-	if !ctx.requestPropagation {
+	properties.SetPhase(properties.PhaseHttpRequestHeaders)
+	// Without correlation header there is nothing to correlate on, also not from filter state
+	if !ctx.requestPropagation || ctx.correlationHeader == "" {
 		return types.ActionContinue
 	}
 
-	reqHeaders := properties.GetRequestHeaders()
-	direction := properties.GetListenerDirection()
+	ctx.snapshot.NextPhase()
+	reqHeaders := ctx.snapshot.RequestHeaders()
+	direction := ctx.snapshot.ListenerDirection()
 
 	cHeaderVal, ok := reqHeaders[strings.ToLower(ctx.correlationHeader)]
 	if !ok {
-		return types.ActionContinue
+		cHeaderVal, ok = ctx.getCorrelationFilterState()
+		if !ok {
+			return types.ActionContinue
+		}
+		setRequestHeader(strings.ToLower(ctx.correlationHeader), cHeaderVal)
+		ctx.snapshot.InvalidateRequestHeaders()
 	}
 
 	switch direction.String() {
@@ -116,6 +190,7 @@ func handleInboundRequest(ctx *httpContext, reqHeaders map[string]string, cHeade
 
 	if !ok {
 		setRequestHeader(pHeaderName, ctx.propagationHeader.Default)
+		ctx.snapshot.InvalidateRequestHeaders()
 		setSharedData(cHeaderVal, ctx.propagationHeader.Default)
 	} else {
 		setSharedData(cHeaderVal, pHeaderVal)
@@ -129,19 +204,36 @@ func handleOutboundRequest(ctx *httpContext, reqHeaders map[string]string, cHead
 		pHeaderVal, err := getSharedData(cHeaderVal)
 		if err == nil {
 			setRequestHeader(pHeaderName, pHeaderVal)
+			ctx.snapshot.InvalidateRequestHeaders()
 		}
 	}
 }
 
 func (ctx *httpContext) OnHttpResponseHeaders(numHeaders int, endOfStream bool) types.Action {
-	if !ctx.responsePropagation {
+	properties.SetPhase(properties.PhaseHttpResponseHeaders)
+	if !ctx.responsePropagation || ctx.correlationHeader == "" {
 		return types.ActionContinue
 	}
 
-	resHeaders := properties.GetResponseHeaders()
-	direction := properties.GetListenerDirection()
+	ctx.snapshot.NextPhase()
+	resHeaders := ctx.snapshot.ResponseHeaders()
+	direction := ctx.snapshot.ListenerDirection()
 
 	cHeaderVal, ok := resHeaders[strings.ToLower(ctx.correlationHeader)]
+	if !ok {
+		cHeaderVal, ok = ctx.getCorrelationFilterState()
+	}
+
+	// gRPC clients often read response metadata from the trailers, so the propagation header
+	// is added to the trailers as well. The headers keep it for clients reading it there and
+	// for responses ending without trailers
+	pHeaderName := strings.ToLower(ctx.propagationHeader.Name)
+	_, hasPHeader := resHeaders[pHeaderName]
+	if isGrpc(resHeaders["content-type"]) && !endOfStream && !hasPHeader {
+		ctx.grpcPending = true
+		ctx.grpcCorrelation = cHeaderVal
+	}
+
 	if !ok {
 		return types.ActionContinue
 	}
@@ -163,6 +255,7 @@ func handleInboundResponse(ctx *httpContext, resHeaders map[string]string, cHead
 		pHeaderVal, err := getSharedData(cHeaderVal)
 		if err == nil {
 			setResponseHeader(pHeaderName, pHeaderVal)
+			ctx.snapshot.InvalidateResponseHeaders()
 		}
 	}
 }
@@ -174,8 +267,103 @@ func handleOutboundResponse(ctx *httpContext, resHeaders map[string]string, cHea
 		pHeaderVal, err := getSharedData(cHeaderVal)
 		if err == nil {
 			setResponseHeader(pHeaderName, pHeaderVal)
+			ctx.snapshot.InvalidateResponseHeaders()
+		}
+	}
+}
+
+func (ctx *httpContext) OnHttpResponseTrailers(numTrailers int) types.Action {
+	properties.SetPhase(properties.PhaseHttpResponseTrailers)
+	if !ctx.responsePropagation || !ctx.grpcPending {
+		return types.ActionContinue
+	}
+
+	ctx.snapshot.NextPhase()
+	resTrailers := ctx.snapshot.ResponseTrailers()
+
+	cTrailerVal, ok := resTrailers[strings.ToLower(ctx.correlationHeader)]
+	if !ok {
+		cTrailerVal, ok = ctx.grpcCorrelation, ctx.grpcCorrelation != ""
+		if !ok {
+			return types.ActionContinue
 		}
 	}
+
+	// Same directions as the propagation header in OnHttpResponseHeaders
+	switch ctx.snapshot.ListenerDirection().String() {
+	case Inbound, Outbound:
+		handleGrpcResponseTrailers(ctx, resTrailers, cTrailerVal)
+	}
+	return types.ActionContinue
+}
+
+func handleGrpcResponseTrailers(ctx *httpContext, resTrailers map[string]string, cTrailerVal string) {
+	pTrailerName := strings.ToLower(ctx.propagationHeader.Name)
+
+	if _, ok := resTrailers[pTrailerName]; !ok {
+		pTrailerVal, err := getSharedData(cTrailerVal)
+		if err == nil {
+			setResponseTrailer(pTrailerName, pTrailerVal)
+		}
+	}
+}
+
+func isGrpc(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), GrpcContentType)
+}
+
+// Correlation value stored by the network filter on the downstream connection, used when the
+// correlation header is missing
+func (ctx *httpContext) getCorrelationFilterState() (string, bool) {
+	if ctx.correlationFilterState == "" {
+		return "", false
+	}
+	cFilterStateVal := ctx.snapshot.GetStable("filter_state.correlation", func() interface{} {
+		return properties.GetWasmFilterStateString(ctx.correlationFilterState)
+	}).(string)
+	return cFilterStateVal, cFilterStateVal != ""
+}
+
+func (p *pluginContext) NewTcpContext(contextID uint32) types.TcpContext {
+	if p.config.NetworkFilter.Source == "" {
+		return nil
+	}
+	return &tcpContext{
+		contextID:              contextID,
+		correlationFilterState: p.config.CorrelationFilterState,
+		networkFilter:          p.config.NetworkFilter,
+	}
+}
+
+type tcpContext struct {
+	types.DefaultTcpContext
+	contextID              uint32
+	correlationFilterState string
+	networkFilter          networkFilter
+}
+
+func (ctx *tcpContext) OnNewConnection() types.Action {
+	properties.SetPhase(properties.PhaseTcpNewConnection)
+	cVal := getConnectionCorrelation(ctx.networkFilter)
+	if cVal == "" {
+		return types.ActionContinue
+	}
+
+	if err := properties.SetFilterStateString(ctx.correlationFilterState, cVal); err != nil {
+		proxywasm.LogErrorf("Failed to set correlation filter state: %v", err)
+	}
+	return types.ActionContinue
+}
+
+// Read the correlation value from the configured connection property
+func getConnectionCorrelation(config networkFilter) string {
+	switch config.Source {
+	case SourceSni:
+		return properties.GetDownstreamRequestedServerName()
+	case SourceProxyTlv:
+		return properties.GetDynamicMetadataValue(config.TlvNamespace, config.TlvKey)
+	}
+	return ""
 }
 
 // Helper functions to reduce repetition
@@ -191,6 +379,12 @@ func setResponseHeader(name, value string) {
 	}
 }
 
+func setResponseTrailer(name, value string) {
+	if err := proxywasm.AddHttpResponseTrailer(name, value); err != nil {
+		proxywasm.LogErrorf("Failed to set response trailer: %v", err)
+	}
+}
+
 func setSharedData(key, value string) {
 	if err := utils.SetSharedDataSafe(key, []byte(value), 0); err != nil {
 		proxywasm.LogErrorf("Failed to set shared data: %v", err)
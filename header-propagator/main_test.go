@@ -0,0 +1,348 @@
+//go:build proxytest
+
+package main
+
+import (
+	"header-propagator/properties"
+	"header-propagator/utils"
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+const testConfig = `{
+	"requestPropagation": true,
+	"responsePropagation": true,
+	"correlationHeader": "x-request-id",
+	"propagationHeader": {"name": "x-tenant", "default": "unknown"}
+}`
+
+// Start the plugin with the given configuration in a new host emulator
+func startTestPlugin(t *testing.T, config string) (proxytest.HostEmulator, func()) {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&vmContext{}).WithPluginConfiguration([]byte(config))
+	host, reset := proxytest.NewHostEmulator(opt)
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		reset()
+		t.Fatalf("expected the plugin to start, got %v", status)
+	}
+	return host, reset
+}
+
+// Set the listener direction property of the emulated proxy
+func setListenerDirection(t *testing.T, host proxytest.HostEmulator, direction properties.TrafficDirection) {
+	t.Helper()
+	if err := host.SetProperty([]string{"listener_direction"}, properties.SerializeUint64(uint64(direction))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Set a header map in the properties of the emulated proxy, where the plugin reads them from
+func setHeaderProperty(t *testing.T, host proxytest.HostEmulator, path []string, headers [][2]string) {
+	t.Helper()
+	m := make(map[string]string, len(headers))
+	for _, header := range headers {
+		m[header[0]] = header[1]
+	}
+	if err := host.SetProperty(path, properties.SerializeStringMap(m)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Call OnHttpRequestHeaders on a new http context, with the headers both in the emulated
+// stream and in the request.headers property
+func callOnRequestHeaders(t *testing.T, host proxytest.HostEmulator, headers [][2]string) uint32 {
+	t.Helper()
+	setHeaderProperty(t, host, []string{"request", "headers"}, headers)
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, headers, false)
+	return contextID
+}
+
+// Call OnHttpResponseHeaders, with the headers both in the emulated stream and in the
+// response.headers property
+func callOnResponseHeaders(t *testing.T, host proxytest.HostEmulator, contextID uint32, headers [][2]string, endOfStream bool) {
+	t.Helper()
+	setHeaderProperty(t, host, []string{"response", "headers"}, headers)
+	host.CallOnResponseHeaders(contextID, headers, endOfStream)
+}
+
+func getHeader(headers [][2]string, name string) (string, bool) {
+	for _, header := range headers {
+		if header[0] == name {
+			return header[1], true
+		}
+	}
+	return "", false
+}
+
+func TestOnPluginStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   types.OnPluginStartStatus
+	}{
+		{"empty", ``, types.OnPluginStartStatusOK},
+		{"http filter", testConfig, types.OnPluginStartStatusOK},
+		{"invalid json", `{"requestPropagation":`, types.OnPluginStartStatusFailed},
+		{"sni", `{"correlationFilterState": "correlation", "networkFilter": {"source": "sni"}}`, types.OnPluginStartStatusOK},
+		{"proxy tlv", `{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv", "tlvKey": "tenant"}}`, types.OnPluginStartStatusOK},
+		{"proxy tlv without key", `{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv"}}`, types.OnPluginStartStatusFailed},
+		{"unknown source", `{"correlationFilterState": "correlation", "networkFilter": {"source": "alpn"}}`, types.OnPluginStartStatusFailed},
+		{"no filter state", `{"networkFilter": {"source": "sni"}}`, types.OnPluginStartStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := proxytest.NewEmulatorOption().WithVMContext(&vmContext{}).WithPluginConfiguration([]byte(tt.config))
+			host, reset := proxytest.NewHostEmulator(opt)
+			defer reset()
+
+			if status := host.StartPlugin(); status != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, status)
+			}
+		})
+	}
+}
+
+func TestInboundRequestDefaultPropagationHeader(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Inbound)
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}, {"x-request-id", "req-1"}})
+
+	if value, _ := getHeader(host.GetCurrentRequestHeaders(contextID), "x-tenant"); value != "unknown" {
+		t.Errorf("expected the default propagation header to be added, got %q", value)
+	}
+	if value, _, err := utils.GetSharedDataSafe("req-1"); err != nil || string(value) != "unknown" {
+		t.Errorf("expected the default value to be stored for the correlation id, got %q (%v)", value, err)
+	}
+}
+
+func TestPropagationFromInboundToOutbound(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+
+	setListenerDirection(t, host, properties.Inbound)
+	callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}, {"x-request-id", "req-1"}, {"x-tenant", "acme"}})
+
+	setListenerDirection(t, host, properties.Outbound)
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/upstream"}, {"x-request-id", "req-1"}})
+	if value, _ := getHeader(host.GetCurrentRequestHeaders(contextID), "x-tenant"); value != "acme" {
+		t.Errorf("expected the propagation header on the outbound request, got %q", value)
+	}
+
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}, {"x-request-id", "req-1"}}, true)
+	if value, _ := getHeader(host.GetCurrentResponseHeaders(contextID), "x-tenant"); value != "acme" {
+		t.Errorf("expected the propagation header on the outbound response, got %q", value)
+	}
+}
+
+func TestRequestWithoutCorrelationHeader(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Inbound)
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}})
+	if _, ok := getHeader(host.GetCurrentRequestHeaders(contextID), "x-tenant"); ok {
+		t.Error("expected no propagation header without a correlation header")
+	}
+}
+
+func TestCorrelationFromFilterState(t *testing.T) {
+	config := `{"requestPropagation": true, "correlationHeader": "x-request-id", "correlationFilterState": "correlation",
+		"propagationHeader": {"name": "x-tenant", "default": "unknown"}}`
+	host, reset := startTestPlugin(t, config)
+	defer reset()
+	setListenerDirection(t, host, properties.Inbound)
+	if err := host.SetProperty([]string{"filter_state", "wasm.correlation"}, []byte("conn-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}})
+	headers := host.GetCurrentRequestHeaders(contextID)
+	if value, _ := getHeader(headers, "x-request-id"); value != "conn-1" {
+		t.Errorf("expected the correlation header from filter state, got %q", value)
+	}
+	if value, _ := getHeader(headers, "x-tenant"); value != "unknown" {
+		t.Errorf("expected the default propagation header, got %q", value)
+	}
+}
+
+func TestGrpcResponseTrailers(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Outbound)
+	if err := utils.SetSharedDataSafe("req-1", []byte("acme"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/pkg.Service/Method"}, {"x-request-id", "req-1"}, {"x-tenant", "acme"}})
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}, {"content-type", "application/grpc"}, {"x-request-id", "req-1"}}, false)
+	if value, _ := getHeader(host.GetCurrentResponseHeaders(contextID), "x-tenant"); value != "acme" {
+		t.Errorf("expected the propagation header on the gRPC response headers, got %q", value)
+	}
+
+	setHeaderProperty(t, host, []string{"response", "trailers"}, [][2]string{{"grpc-status", "0"}})
+	host.CallOnResponseTrailers(contextID, [][2]string{{"grpc-status", "0"}})
+	if value, err := proxywasm.GetHttpResponseTrailer("x-tenant"); err != nil || value != "acme" {
+		t.Errorf("expected the propagation trailer, got %q (%v)", value, err)
+	}
+}
+
+// Like the propagation header, the trailer is only added on inbound and outbound listeners
+func TestGrpcResponseTrailersUnspecifiedDirection(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Unspecified)
+	if err := utils.SetSharedDataSafe("req-1", []byte("acme"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/pkg.Service/Method"}, {"x-request-id", "req-1"}, {"x-tenant", "acme"}})
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}, {"content-type", "application/grpc"}, {"x-request-id", "req-1"}}, false)
+	if _, ok := getHeader(host.GetCurrentResponseHeaders(contextID), "x-tenant"); ok {
+		t.Error("expected no propagation header on an unspecified listener")
+	}
+
+	setHeaderProperty(t, host, []string{"response", "trailers"}, [][2]string{{"grpc-status", "0"}})
+	host.CallOnResponseTrailers(contextID, [][2]string{{"grpc-status", "0"}})
+	if value, err := proxywasm.GetHttpResponseTrailer("x-tenant"); err == nil {
+		t.Errorf("expected no propagation trailer on an unspecified listener, got %q", value)
+	}
+}
+
+// A gRPC stream ending with its body instead of trailers keeps the propagation header
+func TestGrpcResponseWithoutTrailers(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Outbound)
+	if err := utils.SetSharedDataSafe("req-1", []byte("acme"), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/pkg.Service/Method"}, {"x-request-id", "req-1"}, {"x-tenant", "acme"}})
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}, {"content-type", "application/grpc"}, {"x-request-id", "req-1"}}, false)
+	host.CallOnResponseBody(contextID, []byte("message"), true)
+	host.CompleteHttpContext(contextID)
+
+	if value, _ := getHeader(host.GetCurrentResponseHeaders(contextID), "x-tenant"); value != "acme" {
+		t.Errorf("expected the propagation header on the gRPC response headers, got %q", value)
+	}
+}
+
+func TestNetworkFilterSni(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"correlationFilterState": "correlation", "networkFilter": {"source": "sni"}}`)
+	defer reset()
+	if err := host.SetProperty([]string{"connection", "requested_server_name"}, []byte("tenant.example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	host.InitializeConnection()
+	if value, err := host.GetProperty([]string{"correlation"}); err != nil || string(value) != "tenant.example.com" {
+		t.Errorf("expected the sni to be stored in filter state, got %q (%v)", value, err)
+	}
+}
+
+func TestNetworkFilterProxyTlv(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		namespace string
+		key       string
+		want      string
+	}{
+		{
+			"default namespace",
+			`{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv", "tlvKey": "tenant"}}`,
+			"envoy.filters.listener.proxy_protocol", "tenant", "acme",
+		},
+		{
+			"custom namespace",
+			`{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv", "tlvNamespace": "tlv", "tlvKey": "tenant"}}`,
+			"tlv", "tenant", "acme",
+		},
+		{
+			"other namespace",
+			`{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv", "tlvKey": "tenant"}}`,
+			"tlv", "tenant", "",
+		},
+		{
+			"other key",
+			`{"correlationFilterState": "correlation", "networkFilter": {"source": "proxyTlv", "tlvKey": "tenant"}}`,
+			"envoy.filters.listener.proxy_protocol", "vpce", "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, reset := startTestPlugin(t, tt.config)
+			defer reset()
+			path := []string{"metadata", "filter_metadata", tt.namespace, tt.key}
+			if err := host.SetProperty(path, []byte("acme")); err != nil {
+				t.Fatal(err)
+			}
+
+			host.InitializeConnection()
+			value, err := host.GetProperty([]string{"correlation"})
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("expected no filter state without matching tlv, got %q", value)
+				}
+				return
+			}
+			if err != nil || string(value) != tt.want {
+				t.Errorf("expected the tlv to be stored in filter state, got %q (%v)", value, err)
+			}
+		})
+	}
+}
+
+func TestCorrelationFromFilterStateWithoutCorrelationHeader(t *testing.T) {
+	config := `{"requestPropagation": true, "responsePropagation": true, "correlationFilterState": "correlation",
+		"propagationHeader": {"name": "x-tenant", "default": "unknown"}}`
+	host, reset := startTestPlugin(t, config)
+	defer reset()
+	setListenerDirection(t, host, properties.Inbound)
+	if err := host.SetProperty([]string{"filter_state", "wasm.correlation"}, []byte("conn-1")); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}})
+	headers := host.GetCurrentRequestHeaders(contextID)
+	if value, ok := getHeader(headers, ""); ok {
+		t.Errorf("expected no header with an empty name, got %q", value)
+	}
+	if _, ok := getHeader(headers, "x-tenant"); ok {
+		t.Error("expected no propagation header without a correlation header")
+	}
+
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}}, true)
+	if _, ok := getHeader(host.GetCurrentResponseHeaders(contextID), "x-tenant"); ok {
+		t.Error("expected no propagation header on the response without a correlation header")
+	}
+}
+
+// Every callback sets its phase, so phase checks of the properties package never see the
+// phase of a previous callback
+func TestCallbacksSetPhase(t *testing.T) {
+	host, reset := startTestPlugin(t, testConfig)
+	defer reset()
+	setListenerDirection(t, host, properties.Inbound)
+
+	contextID := callOnRequestHeaders(t, host, [][2]string{{":path", "/get"}, {"x-request-id", "req-1"}})
+	if phase := properties.GetPhase(); phase != properties.PhaseHttpRequestHeaders {
+		t.Errorf("expected %v, got %v", properties.PhaseHttpRequestHeaders, phase)
+	}
+	callOnResponseHeaders(t, host, contextID, [][2]string{{":status", "200"}, {"x-request-id", "req-1"}}, false)
+	if phase := properties.GetPhase(); phase != properties.PhaseHttpResponseHeaders {
+		t.Errorf("expected %v, got %v", properties.PhaseHttpResponseHeaders, phase)
+	}
+	host.CallOnResponseTrailers(contextID, nil)
+	if phase := properties.GetPhase(); phase != properties.PhaseHttpResponseTrailers {
+		t.Errorf("expected %v, got %v", properties.PhaseHttpResponseTrailers, phase)
+	}
+}
@@ -2,15 +2,11 @@
 // https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#connection-attributes
 package properties
 
-import (
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
-
 // Get downstream connection remote address
 func GetDownstreamRemoteAddress() string {
 	downstreamRemoteAddress, err := getPropertyString([]string{"source", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading source attribute source.address: %v", err)
+		logPropertyWarnf("failed reading source attribute source.address: %v", err)
 		return ""
 	}
 	return downstreamRemoteAddress
@@ -20,7 +16,7 @@ func GetDownstreamRemoteAddress() string {
 func GetDownstreamRemotePort() int {
 	downstreamRemotePort, err := getPropertyUint64([]string{"source", "port"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading source attribute source.port: %v", err)
+		logPropertyWarnf("failed reading source attribute source.port: %v", err)
 		return 0
 	}
 	return int(downstreamRemotePort)
@@ -30,7 +26,7 @@ func GetDownstreamRemotePort() int {
 func GetDownstreamLocalAddress() string {
 	downstreamLocalAddress, err := getPropertyString([]string{"destination", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading destination attribute destination.address: %v", err)
+		logPropertyWarnf("failed reading destination attribute destination.address: %v", err)
 		return ""
 	}
 	return downstreamLocalAddress
@@ -40,7 +36,7 @@ func GetDownstreamLocalAddress() string {
 func GetDownstreamLocalPort() int {
 	downstreamLocalPort, err := getPropertyUint64([]string{"destination", "port"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading destination attribute destination.port: %v", err)
+		logPropertyWarnf("failed reading destination attribute destination.port: %v", err)
 		return 0
 	}
 	return int(downstreamLocalPort)
@@ -50,7 +46,7 @@ func GetDownstreamLocalPort() int {
 func GetDownstreamConnectionId() uint {
 	downstreamConnectionId, err := getPropertyUint64([]string{"connection", "id"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.id: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.id: %v", err)
 		return 0
 	}
 	return uint(downstreamConnectionId)
@@ -60,7 +56,7 @@ func GetDownstreamConnectionId() uint {
 func IsDownstreamConnectionTls() bool {
 	downstreamConnectionTls, err := getPropertyBool([]string{"connection", "mtls"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.mtls: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.mtls: %v", err)
 		return false
 	}
 	return downstreamConnectionTls
@@ -70,7 +66,7 @@ func IsDownstreamConnectionTls() bool {
 func GetDownstreamRequestedServerName() string {
 	downstreamRequestedServerName, err := getPropertyString([]string{"connection", "requested_server_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.requested_server_name: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.requested_server_name: %v", err)
 		return ""
 	}
 	return downstreamRequestedServerName
@@ -80,7 +76,7 @@ func GetDownstreamRequestedServerName() string {
 func GetDownstreamTlsVersion() string {
 	downstreamTlsVersion, err := getPropertyString([]string{"connection", "tls_version"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.tls_version: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.tls_version: %v", err)
 		return ""
 	}
 	return downstreamTlsVersion
@@ -90,7 +86,7 @@ func GetDownstreamTlsVersion() string {
 func GetDownstreamSubjectLocalCertificate() string {
 	downstreamSubjectLocalCertificate, err := getPropertyString([]string{"connection", "subject_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.subject_local_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.subject_local_certificate: %v", err)
 		return ""
 	}
 	return downstreamSubjectLocalCertificate
@@ -100,7 +96,7 @@ func GetDownstreamSubjectLocalCertificate() string {
 func GetDownstreamSubjectPeerCertificate() string {
 	downstreamSubjectPeerCertificate, err := getPropertyString([]string{"connection", "subject_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.subject_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.subject_peer_certificate: %v", err)
 		return ""
 	}
 	return downstreamSubjectPeerCertificate
@@ -110,7 +106,7 @@ func GetDownstreamSubjectPeerCertificate() string {
 func GetDownstreamDnsSanLocalCertificate() string {
 	downstreamDnsSanLocalCertificate, err := getPropertyString([]string{"connection", "dns_san_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.dns_san_local_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.dns_san_local_certificate: %v", err)
 		return ""
 	}
 	return downstreamDnsSanLocalCertificate
@@ -120,7 +116,7 @@ func GetDownstreamDnsSanLocalCertificate() string {
 func GetDownstreamDnsSanPeerCertificate() string {
 	downstreamDnsSanPeerCertificate, err := getPropertyString([]string{"connection", "dns_san_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.dns_san_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.dns_san_peer_certificate: %v", err)
 		return ""
 	}
 	return downstreamDnsSanPeerCertificate
@@ -130,7 +126,7 @@ func GetDownstreamDnsSanPeerCertificate() string {
 func GetDownstreamUriSanLocalCertificate() string {
 	downstreamUriSanLocalCertificate, err := getPropertyString([]string{"connection", "uri_san_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.uri_san_local_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.uri_san_local_certificate: %v", err)
 		return ""
 	}
 	return downstreamUriSanLocalCertificate
@@ -140,7 +136,7 @@ func GetDownstreamUriSanLocalCertificate() string {
 func GetDownstreamUriSanPeerCertificate() string {
 	downstreamUriSanPeerCertificate, err := getPropertyString([]string{"connection", "uri_san_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.uri_san_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.uri_san_peer_certificate: %v", err)
 		return ""
 	}
 	return downstreamUriSanPeerCertificate
@@ -150,7 +146,7 @@ func GetDownstreamUriSanPeerCertificate() string {
 func GetDownstreamSha256PeerCertificateDigest() string {
 	downstreamSha256PeerCertificateDigest, err := getPropertyString([]string{"connection", "sha256_peer_certificate_digest"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.sha256_peer_certificate_digest: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.sha256_peer_certificate_digest: %v", err)
 		return ""
 	}
 	return downstreamSha256PeerCertificateDigest
@@ -160,8 +156,41 @@ func GetDownstreamSha256PeerCertificateDigest() string {
 func GetDownstreamTerminationDetails() string {
 	downstreamTerminationDetails, err := getPropertyString([]string{"connection", "termination_details"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading connection attribute connection.termination_details: %v", err)
+		logPropertyWarnf("failed reading connection attribute connection.termination_details: %v", err)
 		return ""
 	}
 	return downstreamTerminationDetails
 }
+
+// Get all URI entries in the SAN field of the peer certificate in the downstream TLS connection
+//
+// Envoy only exposes the first URI entry as an attribute, so the result holds at most one
+// element. Callers can rely on the list form should envoy expose all entries in the future
+func GetDownstreamUriSanPeerCertificates() []string {
+	return sanEntries(GetDownstreamUriSanPeerCertificate())
+}
+
+// Get all DNS entries in the SAN field of the peer certificate in the downstream TLS connection
+//
+// Envoy only exposes the first DNS entry as an attribute, so the result holds at most one element
+func GetDownstreamDnsSanPeerCertificates() []string {
+	return sanEntries(GetDownstreamDnsSanPeerCertificate())
+}
+
+// Get downstream transport failure reason e.g. certificate validation failed
+func GetDownstreamTransportFailureReason() string {
+	downstreamTransportFailureReason, err := getPropertyString([]string{"connection", "transport_failure_reason"})
+	if err != nil {
+		logPropertyWarnf("failed reading connection attribute connection.transport_failure_reason: %v", err)
+		return ""
+	}
+	return downstreamTransportFailureReason
+}
+
+// Convert a single SAN attribute value into a list of SAN entries
+func sanEntries(san string) []string {
+	if san == "" {
+		return make([]string, 0)
+	}
+	return []string{san}
+}
@@ -0,0 +1,82 @@
+// Helper function to parse request cookies
+// https://www.rfc-editor.org/rfc/rfc6265#section-5.4
+package properties
+
+import (
+	"strings"
+)
+
+// A single name/value pair of the request cookie header
+type Cookie struct {
+	Name  string
+	Value string
+}
+
+// Ordered list of request cookies. A cookie name can appear multiple times when the client
+// holds cookies with the same name for different paths or domains
+type Cookies []Cookie
+
+// Get the value of the first cookie with the given name, or an empty string if not present
+func (c Cookies) Get(name string) string {
+	for _, cookie := range c {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// Get the values of all cookies with the given name
+func (c Cookies) Values(name string) []string {
+	values := make([]string, 0)
+	for _, cookie := range c {
+		if cookie.Name == name {
+			values = append(values, cookie.Value)
+		}
+	}
+	return values
+}
+
+// Check whether a cookie is present
+func (c Cookies) Has(name string) bool {
+	for _, cookie := range c {
+		if cookie.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the cookies of the request. HTTP/2 clients may split cookies over multiple cookie
+// headers, which are all taken into account
+func GetRequestCookies() Cookies {
+	result := make(Cookies, 0)
+	for _, header := range GetRequestHeaderList().Values("cookie") {
+		result = append(result, ParseCookies(header)...)
+	}
+	return result
+}
+
+// Parse a cookie header value in the format of "name1=value1; name2=value2". Surrounding
+// double quotes are stripped from values and entries without a name are skipped
+func ParseCookies(header string) Cookies {
+	result := make(Cookies, 0)
+	for _, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value := part, ""
+		if i := strings.Index(part, "="); i >= 0 {
+			name, value = strings.TrimSpace(part[:i]), strings.TrimSpace(part[i+1:])
+		}
+		if name == "" {
+			continue
+		}
+		if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		result = append(result, Cookie{Name: name, Value: value})
+	}
+	return result
+}
@@ -0,0 +1,281 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Property store of a host, implemented by proxytest.HostEmulator
+type PropertySetter interface {
+	SetProperty(path []string, data []byte) error
+}
+
+// Attributes the host encodes as timestamp, given as RFC3339 string in fixtures
+var fixtureTimestamps = map[string]bool{
+	"request.time": true,
+}
+
+// Attributes the host encodes as duration, given as duration string (e.g. "1.5ms") in fixtures
+var fixtureDurations = map[string]bool{
+	"request.duration":                true,
+	"response.backend_latency":        true,
+	"upstream.cx_pool_ready_duration": true,
+}
+
+// Attributes holding a protobuf struct, in which all numbers are encoded as float64
+var fixtureStructPrefixes = []string{
+	"node.metadata",
+	"metadata.filter_metadata",
+}
+
+// Load a fixture into the property store of a host (e.g. the proxytest host emulator). The
+// fixture is a JSON document following the attribute paths, with every leaf encoded the way
+// the host encodes the attribute:
+//   - strings as raw bytes, except timestamps and durations
+//   - numbers as uint64, or float64 when they are not integers or part of a protobuf struct
+//   - bools as a single byte and string arrays as string slices
+//   - objects as maps, of which every entry is also set as an attribute of its own
+//
+// The node can also be given as an envoy config dump (the output of the /config_dump admin
+// endpoint), in which case it is taken from the bootstrap config. Empty values are skipped,
+// as the host emulator does not store them
+//
+// Values captured from a real proxy (see the captureFixture option of print-properties) are
+// given in the "raw" list, with the base64 encoded value of every attribute path. They are set
+// as is, so the getters decode exactly what the proxy returned
+//
+// Example fixture:
+//
+//	{
+//		"configs": [{"@type": "type.googleapis.com/envoy.admin.v3.BootstrapConfigDump", "bootstrap": {"node": {...}}}],
+//		"listener_direction": 1,
+//		"request": {"headers": {":path": "/get"}, "time": "2023-10-18T09:12:43.115Z"},
+//		"raw": [{"path": ["node", "metadata", "PROXY_CONFIG"], "value": "BgAAAAoAAAAM..."}]
+//	}
+func LoadFixture(host PropertySetter, data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var fixture map[string]interface{}
+	if err := decoder.Decode(&fixture); err != nil {
+		return fmt.Errorf("failed decoding fixture: %v", err)
+	}
+
+	raw, hasRaw := fixture["raw"]
+	delete(fixture, "raw")
+
+	if configs, ok := fixture["configs"]; ok {
+		delete(fixture, "configs")
+		if node := bootstrapNode(configs); node != nil {
+			fixture["node"] = node
+		}
+	}
+
+	for _, key := range sortedKeys(fixture) {
+		if err := setFixtureValue(host, []string{key}, fixture[key]); err != nil {
+			return err
+		}
+	}
+
+	// Captured values are set last, so they take precedence over the encoded attributes
+	if hasRaw {
+		return setRawFixtureValues(host, raw)
+	}
+	return nil
+}
+
+// Find the node of the bootstrap config in the configs of a config dump
+func bootstrapNode(configs interface{}) interface{} {
+	list, _ := configs.([]interface{})
+	for _, config := range list {
+		config, _ := config.(map[string]interface{})
+		bootstrap, _ := config["bootstrap"].(map[string]interface{})
+		if node, ok := bootstrap["node"]; ok {
+			return node
+		}
+	}
+	return nil
+}
+
+// Set the captured attribute values of the raw list of a fixture
+func setRawFixtureValues(host PropertySetter, raw interface{}) error {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected a list for the raw fixture values")
+	}
+	for _, entry := range list {
+		entry, _ := entry.(map[string]interface{})
+		path, err := fixtureStrings("raw.path", entry["path"])
+		if err != nil || len(path) == 0 {
+			return fmt.Errorf("expected a path for raw fixture value %v", entry)
+		}
+		data, err := base64.StdEncoding.DecodeString(fmt.Sprint(entry["value"]))
+		if err != nil {
+			return fmt.Errorf("invalid raw fixture value for %v: %v", strings.Join(path, "."), err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := host.SetProperty(path, data); err != nil {
+			return fmt.Errorf("failed setting raw fixture attribute %v: %v", strings.Join(path, "."), err)
+		}
+	}
+	return nil
+}
+
+// Set an attribute and, for objects, all its entries
+func setFixtureValue(host PropertySetter, path []string, value interface{}) error {
+	if object, ok := value.(map[string]interface{}); ok {
+		for _, key := range sortedKeys(object) {
+			if err := setFixtureValue(host, append(path[:len(path):len(path)], key), object[key]); err != nil {
+				return err
+			}
+		}
+	}
+
+	attribute := strings.Join(path, ".")
+	data, err := encodeFixtureValue(attribute, value)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := host.SetProperty(path, data); err != nil {
+		return fmt.Errorf("failed setting fixture attribute %v: %v", attribute, err)
+	}
+	return nil
+}
+
+// Encode a fixture value the way the host encodes the given attribute
+func encodeFixtureValue(attribute string, value interface{}) ([]byte, error) {
+	switch {
+	case fixtureTimestamps[attribute]:
+		timestamp, err := time.Parse(time.RFC3339Nano, fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp for fixture attribute %v: %v", attribute, err)
+		}
+		return SerializeTimestamp(timestamp), nil
+	case fixtureDurations[attribute]:
+		duration, err := time.ParseDuration(fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for fixture attribute %v: %v", attribute, err)
+		}
+		return SerializeDuration(duration), nil
+	case attribute == "node.client_features":
+		features, err := fixtureStrings(attribute, value)
+		if err != nil {
+			return nil, err
+		}
+		return SerializeProtobufStringSlice(features), nil
+	case attribute == "node.extensions":
+		return encodeFixtureExtensions(value)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case bool:
+		return SerializeBool(v), nil
+	case json.Number:
+		if !inFixtureStruct(attribute) {
+			if number, err := v.Int64(); err == nil && number >= 0 {
+				return SerializeUint64(uint64(number)), nil
+			}
+		}
+		number, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number for fixture attribute %v: %v", attribute, err)
+		}
+		return SerializeFloat64(number), nil
+	case []interface{}:
+		values, err := fixtureStrings(attribute, v)
+		if err != nil {
+			return nil, err
+		}
+		return SerializeStringSlice(values), nil
+	case map[string]interface{}:
+		entries := make(map[string][]byte, len(v))
+		for key, entry := range v {
+			data, err := encodeFixtureValue(attribute+"."+key, entry)
+			if err != nil {
+				return nil, err
+			}
+			if data != nil {
+				entries[key] = data
+			}
+		}
+		return SerializeByteMap(entries), nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v for fixture attribute %v", value, attribute)
+	}
+}
+
+// Encode node.extensions, given as the objects of a config dump, as the list of protobuf
+// encoded name, category and type urls of every extension
+func encodeFixtureExtensions(value interface{}) ([]byte, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list for fixture attribute node.extensions")
+	}
+	extensions := make([][]byte, 0, len(list))
+	for _, entry := range list {
+		extension, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected objects in fixture attribute node.extensions")
+		}
+		fields := []string{fmt.Sprint(extension["name"]), fmt.Sprint(extension["category"])}
+		if typeUrls, ok := extension["type_urls"]; ok {
+			urls, err := fixtureStrings("node.extensions.type_urls", typeUrls)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, urls...)
+		}
+		extensions = append(extensions, SerializeProtobufStringSlice(fields))
+	}
+	return SerializeByteSliceSlice(extensions), nil
+}
+
+// Convert a fixture list of strings
+func fixtureStrings(attribute string, value interface{}) ([]string, error) {
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a list for fixture attribute %v", attribute)
+	}
+	values := make([]string, 0, len(list))
+	for _, entry := range list {
+		str, ok := entry.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected only strings in fixture attribute %v", attribute)
+		}
+		values = append(values, str)
+	}
+	return values, nil
+}
+
+func inFixtureStruct(attribute string) bool {
+	for _, prefix := range fixtureStructPrefixes {
+		if strings.HasPrefix(attribute, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,138 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+const testFixture = `{
+	"configs": [{
+		"@type": "type.googleapis.com/envoy.admin.v3.BootstrapConfigDump",
+		"bootstrap": {
+			"node": {
+				"id": "sidecar~10.244.0.12~httpbin-7b5f8d8c9d-x2x6n.default~default.svc.cluster.local",
+				"cluster": "httpbin.default",
+				"metadata": {
+					"ENVOY_PROMETHEUS_PORT": 15090,
+					"LABELS": {"app": "httpbin", "version": "v1"},
+					"NAMESPACE": "default",
+					"PILOT_SAN": ["istiod.istio-system.svc"],
+					"PROXY_CONFIG": {
+						"concurrency": 2,
+						"drainDuration": "45s",
+						"holdApplicationUntilProxyStarts": true,
+						"tracing": {"zipkin": {"address": "zipkin.istio-system:9411"}}
+					}
+				},
+				"client_features": ["envoy.lb.does_not_support_overprovisioning"],
+				"extensions": [{
+					"name": "envoy.filters.http.wasm",
+					"category": "envoy.filters.http",
+					"type_urls": ["envoy.extensions.filters.http.wasm.v3.Wasm"]
+				}]
+			}
+		}
+	}],
+	"listener_direction": 1,
+	"request": {
+		"headers": {":path": "/get", "x-request-id": "req-1"},
+		"path": "/get",
+		"time": "2023-10-18T09:12:43.115Z",
+		"duration": "1.5ms",
+		"referer": ""
+	},
+	"filter_state": {"wasm.correlation": "conn-1"}
+}`
+
+// Create a host emulator with the test fixture loaded
+func newFixtureHost(t *testing.T) func() {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{})
+	host, reset := proxytest.NewHostEmulator(opt)
+	if err := LoadFixture(host, []byte(testFixture)); err != nil {
+		reset()
+		t.Fatal(err)
+	}
+	return reset
+}
+
+func TestLoadFixture(t *testing.T) {
+	reset := newFixtureHost(t)
+	defer reset()
+
+	if got := GetNodeCluster(); got != "httpbin.default" {
+		t.Errorf("node.cluster: got %q", got)
+	}
+	if got := GetNodeMetadataNamespace(); got != "default" {
+		t.Errorf("node.metadata.NAMESPACE: got %q", got)
+	}
+	if got := GetNodeMetadataLabels(); !reflect.DeepEqual(got, map[string]string{"app": "httpbin", "version": "v1"}) {
+		t.Errorf("node.metadata.LABELS: got %v", got)
+	}
+	if got := GetNodeMetadataPilotSan(); !reflect.DeepEqual(got, []string{"istiod.istio-system.svc"}) {
+		t.Errorf("node.metadata.PILOT_SAN: got %q", got)
+	}
+	if got := GetNodeMetadataEnvoyPrometheusPort(); got != 15090 {
+		t.Errorf("node.metadata.ENVOY_PROMETHEUS_PORT: got %v", got)
+	}
+
+	proxyConfig := GetNodeProxyConfig()
+	if proxyConfig.Concurrency != 2 || proxyConfig.DrainDuration != 45*time.Second ||
+		!proxyConfig.HoldApplicationUntilProxyStarts || proxyConfig.Tracing.ZipkinAddress != "zipkin.istio-system:9411" {
+		t.Errorf("node.metadata.PROXY_CONFIG: got %+v", proxyConfig)
+	}
+	if got := GetNodeProxyConfigConcurrency(); got != 2 {
+		t.Errorf("node.metadata.PROXY_CONFIG.concurrency: got %v", got)
+	}
+
+	if got := GetNodeClientFeatures(); !reflect.DeepEqual(got, []string{"envoy.lb.does_not_support_overprovisioning"}) {
+		t.Errorf("node.client_features: got %q", got)
+	}
+	extensions := GetNodeExtensions()
+	if len(extensions) != 1 || extensions[0].Name != "envoy.filters.http.wasm" || extensions[0].Category != "envoy.filters.http" ||
+		!reflect.DeepEqual(extensions[0].TypeUrls, []string{"envoy.extensions.filters.http.wasm.v3.Wasm"}) {
+		t.Errorf("node.extensions: got %+v", extensions)
+	}
+
+	if got := GetListenerDirection(); got != Inbound {
+		t.Errorf("listener_direction: got %v", got)
+	}
+	if got := GetRequestHeaders(); !reflect.DeepEqual(got, map[string]string{":path": "/get", "x-request-id": "req-1"}) {
+		t.Errorf("request.headers: got %v", got)
+	}
+	if got := GetRequestPath(); got != "/get" {
+		t.Errorf("request.path: got %q", got)
+	}
+	if got := GetRequestTime(); !got.Equal(time.Date(2023, 10, 18, 9, 12, 43, 115000000, time.UTC)) {
+		t.Errorf("request.time: got %v", got)
+	}
+	if got := GetRequestDuration(); got != 1500*time.Microsecond {
+		t.Errorf("request.duration: got %v", got)
+	}
+	if got := GetWasmFilterStateString("correlation"); got != "conn-1" {
+		t.Errorf("filter_state.wasm.correlation: got %q", got)
+	}
+}
+
+func TestLoadFixtureInvalid(t *testing.T) {
+	opt := proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{})
+	host, reset := proxytest.NewHostEmulator(opt)
+	defer reset()
+
+	for _, fixture := range []string{
+		`{"request": `,
+		`{"request": {"time": "yesterday"}}`,
+		`{"request": {"duration": 12}}`,
+		`{"node": {"listening_addresses": [1, 2]}}`,
+	} {
+		if err := LoadFixture(host, []byte(fixture)); err == nil {
+			t.Errorf("expected an error for fixture %v", fixture)
+		}
+	}
+}
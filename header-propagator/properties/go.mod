@@ -1,3 +1,7 @@
 module header-propagator/properties
 
-go 1.19
\ No newline at end of file
+go 1.19
+
+require github.com/tetratelabs/proxy-wasm-go-sdk v0.22.0
+
+require github.com/tetratelabs/wazero v1.0.0-rc.1 // indirect
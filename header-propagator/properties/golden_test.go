@@ -0,0 +1,146 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the fixtures in testdata/fixtures")
+
+// Getters replayed against every fixture. They cover the attributes whose encoding is known to
+// differ between proxy versions (protobuf struct numbers and bools, maps, slices and timestamps)
+var goldenGetters = map[string]func() interface{}{
+	"GetListenerDirection":               func() interface{} { return GetListenerDirection().String() },
+	"GetNodeId":                          func() interface{} { return GetNodeId() },
+	"GetNodeCluster":                     func() interface{} { return GetNodeCluster() },
+	"GetNodeUserAgentName":               func() interface{} { return GetNodeUserAgentName() },
+	"GetNodeExtensions":                  func() interface{} { return GetNodeExtensions() },
+	"GetNodeMetadataClusterId":           func() interface{} { return GetNodeMetadataClusterId() },
+	"GetNodeMetadataEnvoyPrometheusPort": func() interface{} { return GetNodeMetadataEnvoyPrometheusPort() },
+	"GetNodeMetadataEnvoyStatusPort":     func() interface{} { return GetNodeMetadataEnvoyStatusPort() },
+	"GetNodeMetadataIstioVersion":        func() interface{} { return GetNodeMetadataIstioVersion() },
+	"GetNodeMetadataLabels":              func() interface{} { return GetNodeMetadataLabels() },
+	"GetNodeMetadataMeshId":              func() interface{} { return GetNodeMetadataMeshId() },
+	"GetNodeMetadataName":                func() interface{} { return GetNodeMetadataName() },
+	"GetNodeMetadataNamespace":           func() interface{} { return GetNodeMetadataNamespace() },
+	"GetNodeMetadataPilotSan":            func() interface{} { return GetNodeMetadataPilotSan() },
+	"GetNodeMetadataServiceAccount":      func() interface{} { return GetNodeMetadataServiceAccount() },
+	"GetNodeMetadataWorkloadName":        func() interface{} { return GetNodeMetadataWorkloadName() },
+	"GetNodeProxyConfig":                 func() interface{} { return GetNodeProxyConfig() },
+	"GetNodeProxyConfigConcurrency":      func() interface{} { return GetNodeProxyConfigConcurrency() },
+	"GetNodeProxyConfigProxyAdminPort":   func() interface{} { return GetNodeProxyConfigProxyAdminPort() },
+	"GetNodeProxyConfigStatNameLength":   func() interface{} { return GetNodeProxyConfigStatNameLength() },
+	"GetNodeProxyConfigStatusPort":       func() interface{} { return GetNodeProxyConfigStatusPort() },
+	"GetNodeProxyConfigHoldApplicationUntilProxyStarts": func() interface{} {
+		return GetNodeProxyConfigHoldApplicationUntilProxyStarts()
+	},
+	"GetRequestHeaders": func() interface{} { return GetRequestHeaders() },
+	"GetRequestPath":    func() interface{} { return GetRequestPath() },
+	"GetRequestTime": func() interface{} {
+		// GetRequestTime falls back to the current time, which would break the comparison
+		requestTime, err := getPropertTimestamp([]string{"request", "time"})
+		if err != nil {
+			return nil
+		}
+		return requestTime.UTC()
+	},
+	"GetRequestDuration":        func() interface{} { return GetRequestDuration().String() },
+	"GetResponseCode":           func() interface{} { return GetResponseCode() },
+	"GetResponseFlags":          func() interface{} { return GetResponseFlags().String() },
+	"GetDownstreamPeerMetadata": func() interface{} { return GetDownstreamPeerMetadata() },
+}
+
+// Replay every fixture through the getters and compare the decoded values with the golden
+// file next to it. Run with -update to accept intended changes
+func TestGoldenFixtures(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "fixtures", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".golden.json") {
+			continue
+		}
+		name := strings.TrimSuffix(filepath.Base(fixture), ".json")
+		t.Run(name, func(t *testing.T) {
+			got := replayFixture(t, fixture)
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run the test with -update to create it: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("decoded values of %v differ from %v:\n%s", fixture, golden, got)
+			}
+		})
+	}
+}
+
+// Istio versions and proxy types a fixture must be captured from, see testdata/README.md
+var (
+	goldenIstioVersions = []string{"1.18", "1.19", "1.20", "1.21", "1.22"}
+	goldenProxyTypes    = []string{"sidecar", "gateway"}
+)
+
+// Report every istio version and proxy type without a captured fixture as skipped, so the
+// missing captures show up in the test output until they are committed
+func TestGoldenFixtureCaptures(t *testing.T) {
+	for _, version := range goldenIstioVersions {
+		for _, proxyType := range goldenProxyTypes {
+			t.Run(version+"-"+proxyType, func(t *testing.T) {
+				pattern := filepath.Join("testdata", "fixtures", "istio-"+version+".*-"+proxyType+".json")
+				captures, err := filepath.Glob(pattern)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(captures) == 0 {
+					t.Skipf("no fixture captured from an istio %v %v proxy yet", version, proxyType)
+				}
+			})
+		}
+	}
+}
+
+// Load a fixture in a new host emulator and return the decoded values of all golden getters
+func replayFixture(t *testing.T, fixture string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host, reset := proxytest.NewHostEmulator(proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{}))
+	defer reset()
+	if err := LoadFixture(host, data); err != nil {
+		t.Fatal(err)
+	}
+
+	SetPhase(PhaseUnknown)
+	values := make(map[string]interface{}, len(goldenGetters))
+	for name, getter := range goldenGetters {
+		values[name] = getter()
+	}
+	decoded, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(decoded, '\n')
+}
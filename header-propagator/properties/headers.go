@@ -0,0 +1,102 @@
+// Helper type to work with header maps that contain duplicate headers
+// https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#request-attributes
+package properties
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+)
+
+// Ordered list of header name/value pairs, preserving duplicate headers (e.g. multiple
+// set-cookie or x-forwarded-for entries). Name lookups are case-insensitive
+type Headers [][2]string
+
+// Get the first value of a header, or an empty string if the header is not present
+func (h Headers) Get(name string) string {
+	for _, header := range h {
+		if strings.EqualFold(header[0], name) {
+			return header[1]
+		}
+	}
+	return ""
+}
+
+// Get all values of a header in the order they were received
+func (h Headers) Values(name string) []string {
+	values := make([]string, 0)
+	for _, header := range h {
+		if strings.EqualFold(header[0], name) {
+			values = append(values, header[1])
+		}
+	}
+	return values
+}
+
+// Check whether a header is present
+func (h Headers) Has(name string) bool {
+	for _, header := range h {
+		if strings.EqualFold(header[0], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the distinct header names in the order they were first received
+func (h Headers) Names() []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, header := range h {
+		name := strings.ToLower(header[0])
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Get all request headers including duplicates. Within an http callback the headers are read
+// through the http header API, elsewhere the request.headers attribute is used, in which case
+// envoy already merged duplicate headers
+func GetRequestHeaderList() Headers {
+	requestHeaders, err := proxywasm.GetHttpRequestHeaders()
+	if err == nil {
+		return Headers(requestHeaders)
+	}
+	return headersFromMap(GetRequestHeaders())
+}
+
+// Get all response headers including duplicates. Within an http callback the headers are read
+// through the http header API, elsewhere the response.headers attribute is used, in which case
+// envoy already merged duplicate headers
+func GetResponseHeaderList() Headers {
+	responseHeaders, err := proxywasm.GetHttpResponseHeaders()
+	if err == nil {
+		return Headers(responseHeaders)
+	}
+	return headersFromMap(GetResponseHeaders())
+}
+
+// Get all response trailers including duplicates. Within an http callback the trailers are read
+// through the http trailer API, elsewhere the response.trailers attribute is used, in which case
+// envoy already merged duplicate trailers
+func GetResponseTrailerList() Headers {
+	responseTrailers, err := proxywasm.GetHttpResponseTrailers()
+	if err == nil {
+		return Headers(responseTrailers)
+	}
+	return headersFromMap(GetResponseTrailers())
+}
+
+// Convert a header map into a header list, sorted by name as maps carry no order
+func headersFromMap(m map[string]string) Headers {
+	result := make(Headers, 0, len(m))
+	for name, value := range m {
+		result = append(result, [2]string{name, value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result
+}
@@ -0,0 +1,77 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	headers := Headers{
+		{":path", "/get"},
+		{"Set-Cookie", "a=1"},
+		{"x-forwarded-for", "10.0.0.1"},
+		{"set-cookie", "b=2"},
+		{"X-Forwarded-For", "10.0.0.2"},
+		{"x-empty", ""},
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		wantGet    string
+		wantValues []string
+		wantHas    bool
+	}{
+		{"single header", ":path", "/get", []string{"/get"}, true},
+		{"case-insensitive lookup", "SET-COOKIE", "a=1", []string{"a=1", "b=2"}, true},
+		{"repeated header", "x-forwarded-for", "10.0.0.1", []string{"10.0.0.1", "10.0.0.2"}, true},
+		{"empty value", "x-empty", "", []string{""}, true},
+		{"missing header", "authorization", "", []string{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headers.Get(tt.header); got != tt.wantGet {
+				t.Errorf("Get(%q): got %q, want %q", tt.header, got, tt.wantGet)
+			}
+			if got := headers.Values(tt.header); !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("Values(%q): got %q, want %q", tt.header, got, tt.wantValues)
+			}
+			if got := headers.Has(tt.header); got != tt.wantHas {
+				t.Errorf("Has(%q): got %v, want %v", tt.header, got, tt.wantHas)
+			}
+		})
+	}
+
+	wantNames := []string{":path", "set-cookie", "x-forwarded-for", "x-empty"}
+	if got := headers.Names(); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("Names(): got %q, want %q", got, wantNames)
+	}
+	if got := (Headers{}).Names(); len(got) != 0 {
+		t.Errorf("Names() of empty headers: got %q", got)
+	}
+}
+
+func TestHeadersFromMap(t *testing.T) {
+	tests := []struct {
+		name string
+		m    map[string]string
+		want Headers
+	}{
+		{"empty", map[string]string{}, Headers{}},
+		{"nil", nil, Headers{}},
+		{
+			"sorted by name",
+			map[string]string{"x-request-id": "42", ":path": "/get", "accept": "*/*", ":authority": "httpbin"},
+			Headers{{":authority", "httpbin"}, {":path", "/get"}, {"accept", "*/*"}, {"x-request-id", "42"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headersFromMap(tt.m); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("headersFromMap(%v): got %q, want %q", tt.m, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Helper function to retreive dynamic metadata properties
+// https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#metadata-and-filter-state
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/advanced/well_known_dynamic_metadata
+package properties
+
+import "strings"
+
+// Dynamic metadata is emitted by filters during request processing and is
+// structured as a map from filter name (in reverse DNS format, e.g.
+// "envoy.filters.http.jwt_authn") to filter specific key-values. Envoy does not
+// allow wasm plugins to write dynamic metadata directly, use SetFilterState to
+// hand data to later filters and access loggers instead
+
+// Get all string key-values of a dynamic metadata namespace
+//
+// Example: GetDynamicMetadata("envoy.filters.http.jwt_authn")
+func GetDynamicMetadata(namespace string) map[string]string {
+	dynamicMetadata, err := getPropertyStringMap([]string{"metadata", "filter_metadata", namespace})
+	if err != nil {
+		logPropertyWarnf("failed reading dynamic metadata attribute metadata.filter_metadata.%v: %v", namespace, err)
+		return make(map[string]string)
+	}
+	return dynamicMetadata
+}
+
+// Get a string value from a dynamic metadata namespace. Nested struct values can be
+// reached by passing the intermediate keys
+//
+// Example: GetDynamicMetadataValue("envoy.lb", "canary")
+func GetDynamicMetadataValue(namespace string, keys ...string) string {
+	path := append([]string{"metadata", "filter_metadata", namespace}, keys...)
+	dynamicMetadataValue, err := getPropertyString(path)
+	if err != nil {
+		logPropertyWarnf("failed reading dynamic metadata attribute %v: %v", strings.Join(path, "."), err)
+		return ""
+	}
+	return dynamicMetadataValue
+}
+
+// Get a boolean value from a dynamic metadata namespace. Nested struct values can be
+// reached by passing the intermediate keys
+func GetDynamicMetadataBool(namespace string, keys ...string) bool {
+	path := append([]string{"metadata", "filter_metadata", namespace}, keys...)
+	dynamicMetadataBool, err := getPropertyBool(path)
+	if err != nil {
+		logPropertyWarnf("failed reading dynamic metadata attribute %v: %v", strings.Join(path, "."), err)
+		return false
+	}
+	return dynamicMetadataBool
+}
+
+// Get a number value from a dynamic metadata namespace. Protobuf struct numbers are
+// always encoded as doubles. Nested struct values can be reached by passing the
+// intermediate keys
+func GetDynamicMetadataNumber(namespace string, keys ...string) float64 {
+	path := append([]string{"metadata", "filter_metadata", namespace}, keys...)
+	dynamicMetadataNumber, err := getPropertyFloat64(path)
+	if err != nil {
+		logPropertyWarnf("failed reading dynamic metadata attribute %v: %v", strings.Join(path, "."), err)
+		return 0
+	}
+	return dynamicMetadataNumber
+}
@@ -4,7 +4,7 @@
 // https://istio.io/latest/docs/reference/config/istio.mesh.v1alpha1/#ProxyConfig
 package properties
 
-import "github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+import "time"
 
 // Istio pilot specific section
 // https://pkg.go.dev/istio.io/istio/pilot/pkg/model
@@ -13,7 +13,7 @@ import "github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
 func GetNodeMetadataAnnotations() map[string]string {
 	annotations, err := getPropertyStringMap([]string{"node", "metadata", "ANNOTATIONS"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.ANNOTATIONS: %v", err)
+		logPropertyWarnf("failed reading node.metadata.ANNOTATIONS: %v", err)
 		return make(map[string]string)
 	}
 	return annotations
@@ -23,7 +23,7 @@ func GetNodeMetadataAnnotations() map[string]string {
 func GetNodeMetadataAppContainers() string {
 	appContainers, err := getPropertyString([]string{"node", "metadata", "APP_CONTAINERS"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.APP_CONTAINERS: %v", err)
+		logPropertyWarnf("failed reading node.metadata.APP_CONTAINERS: %v", err)
 		return ""
 	}
 	return appContainers
@@ -33,7 +33,7 @@ func GetNodeMetadataAppContainers() string {
 func GetNodeMetadataClusterId() string {
 	clusterId, err := getPropertyString([]string{"node", "metadata", "CLUSTER_ID"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.CLUSTER_ID: %v", err)
+		logPropertyWarnf("failed reading node.metadata.CLUSTER_ID: %v", err)
 		return ""
 	}
 	return clusterId
@@ -43,7 +43,7 @@ func GetNodeMetadataClusterId() string {
 func GetNodeMetadataEnvoyPrometheusPort() int {
 	envoyPrometheusPortFloat64, err := getPropertyFloat64([]string{"node", "metadata", "ENVOY_PROMETHEUS_PORT"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.ENVOY_PROMETHEUS_PORT: %v", err)
+		logPropertyWarnf("failed reading node.metadata.ENVOY_PROMETHEUS_PORT: %v", err)
 		return 0
 	}
 	return int(envoyPrometheusPortFloat64)
@@ -53,7 +53,7 @@ func GetNodeMetadataEnvoyPrometheusPort() int {
 func GetNodeMetadataEnvoyStatusPort() int {
 	envoyStatusPortFloat64, err := getPropertyFloat64([]string{"node", "metadata", "ENVOY_STATUS_PORT"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.ENVOY_STATUS_PORT: %v", err)
+		logPropertyWarnf("failed reading node.metadata.ENVOY_STATUS_PORT: %v", err)
 		return 0
 	}
 	return int(envoyStatusPortFloat64)
@@ -63,7 +63,7 @@ func GetNodeMetadataEnvoyStatusPort() int {
 func GetNodeMetadataInstanceIps() string {
 	instanceIps, err := getPropertyString([]string{"node", "metadata", "INSTANCE_IPS"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.INSTANCE_IPS: %v", err)
+		logPropertyWarnf("failed reading node.metadata.INSTANCE_IPS: %v", err)
 		return ""
 	}
 	return instanceIps
@@ -84,7 +84,7 @@ func GetNodeMetadataInstanceIps() string {
 func GetNodeMetadataInterceptionMode() string {
 	interceptionMode, err := getPropertyString([]string{"node", "metadata", "INTERCEPTION_MODE"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.INTERCEPTION_MODE: %v", err)
+		logPropertyWarnf("failed reading node.metadata.INTERCEPTION_MODE: %v", err)
 		return ""
 	}
 	return interceptionMode
@@ -94,7 +94,7 @@ func GetNodeMetadataInterceptionMode() string {
 func GetNodeMetadataIstioProxySha() string {
 	istioProxySha, err := getPropertyString([]string{"node", "metadata", "ISTIO_PROXY_SHA"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.ISTIO_PROXY_SHA: %v", err)
+		logPropertyWarnf("failed reading node.metadata.ISTIO_PROXY_SHA: %v", err)
 		return ""
 	}
 	return istioProxySha
@@ -104,7 +104,7 @@ func GetNodeMetadataIstioProxySha() string {
 func GetNodeMetadataIstioVersion() string {
 	istioVersion, err := getPropertyString([]string{"node", "metadata", "ISTIO_VERSION"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.ISTIO_VERSION: %v", err)
+		logPropertyWarnf("failed reading node.metadata.ISTIO_VERSION: %v", err)
 		return ""
 	}
 	return istioVersion
@@ -114,7 +114,7 @@ func GetNodeMetadataIstioVersion() string {
 func GetNodeMetadataLabels() map[string]string {
 	labels, err := getPropertyStringMap([]string{"node", "metadata", "LABELS"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.LABELS: %v", err)
+		logPropertyWarnf("failed reading node.metadata.LABELS: %v", err)
 		return make(map[string]string)
 	}
 	return labels
@@ -124,7 +124,7 @@ func GetNodeMetadataLabels() map[string]string {
 func GetNodeMetadataMeshId() string {
 	meshId, err := getPropertyString([]string{"node", "metadata", "MESH_ID"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.MESH_ID: %v", err)
+		logPropertyWarnf("failed reading node.metadata.MESH_ID: %v", err)
 		return ""
 	}
 	return meshId
@@ -135,7 +135,7 @@ func GetNodeMetadataMeshId() string {
 func GetNodeMetadataName() string {
 	name, err := getPropertyString([]string{"node", "metadata", "NAME"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.NAME: %v", err)
+		logPropertyWarnf("failed reading node.metadata.NAME: %v", err)
 		return ""
 	}
 	return name
@@ -145,7 +145,7 @@ func GetNodeMetadataName() string {
 func GetNodeMetadataNamespace() string {
 	namespace, err := getPropertyString([]string{"node", "metadata", "NAMESPACE"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.NAMESPACE: %v", err)
+		logPropertyWarnf("failed reading node.metadata.NAMESPACE: %v", err)
 		return ""
 	}
 	return namespace
@@ -155,7 +155,7 @@ func GetNodeMetadataNamespace() string {
 func GetNodeMetadataNodeName() string {
 	nodeName, err := getPropertyString([]string{"node", "metadata", "NODE_NAME"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.NODE_NAME: %v", err)
+		logPropertyWarnf("failed reading node.metadata.NODE_NAME: %v", err)
 		return ""
 	}
 	return nodeName
@@ -166,7 +166,7 @@ func GetNodeMetadataNodeName() string {
 func GetNodeMetadataOwner() string {
 	owner, err := getPropertyString([]string{"node", "metadata", "OWNER"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.OWNER: %v", err)
+		logPropertyWarnf("failed reading node.metadata.OWNER: %v", err)
 		return ""
 	}
 	return owner
@@ -176,7 +176,7 @@ func GetNodeMetadataOwner() string {
 func GetNodeMetadataPilotSan() []string {
 	pilotSan, err := getPropertyStringSlice([]string{"node", "metadata", "PILOT_SAN"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PILOT_SAN: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PILOT_SAN: %v", err)
 		return make([]string, 0)
 	}
 	return pilotSan
@@ -186,7 +186,7 @@ func GetNodeMetadataPilotSan() []string {
 func GetNodeMetadataPodPorts() string {
 	podPorts, err := getPropertyString([]string{"node", "metadata", "POD_PORTS"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.POD_PORTS: %v", err)
+		logPropertyWarnf("failed reading node.metadata.POD_PORTS: %v", err)
 		return ""
 	}
 	return podPorts
@@ -196,7 +196,7 @@ func GetNodeMetadataPodPorts() string {
 func GetNodeMetadataServiceAccount() string {
 	serviceAccount, err := getPropertyString([]string{"node", "metadata", "SERVICE_ACCOUNT"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.SERVICE_ACCOUNT: %v", err)
+		logPropertyWarnf("failed reading node.metadata.SERVICE_ACCOUNT: %v", err)
 		return ""
 	}
 	return serviceAccount
@@ -206,7 +206,7 @@ func GetNodeMetadataServiceAccount() string {
 func GetNodeMetadataWorkloadName() string {
 	workloadName, err := getPropertyString([]string{"node", "metadata", "WORKLOAD_NAME"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.WORKLOAD_NAME: %v", err)
+		logPropertyWarnf("failed reading node.metadata.WORKLOAD_NAME: %v", err)
 		return ""
 	}
 	return workloadName
@@ -219,7 +219,7 @@ func GetNodeMetadataWorkloadName() string {
 func GetNodeProxyConfigBinaryPath() string {
 	binaryPath, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "binaryPath"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.binaryPath: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.binaryPath: %v", err)
 		return ""
 	}
 	return binaryPath
@@ -231,7 +231,7 @@ func GetNodeProxyConfigBinaryPath() string {
 func GetNodeProxyConfigConcurrency() int {
 	concurrencyFloat64, err := getPropertyFloat64([]string{"node", "metadata", "PROXY_CONFIG", "concurrency"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.concurrency: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.concurrency: %v", err)
 		return 0
 	}
 	return int(concurrencyFloat64)
@@ -242,7 +242,7 @@ func GetNodeProxyConfigConcurrency() int {
 func GetNodeProxyConfigConfigPath() string {
 	configPath, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "configPath"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.configPath: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.configPath: %v", err)
 		return ""
 	}
 	return configPath
@@ -253,7 +253,7 @@ func GetNodeProxyConfigConfigPath() string {
 func GetNodeProxyConfigControlPlaneAuthPolicy() string {
 	controlPlaneAuthPolicy, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "controlPlaneAuthPolicy"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.controlPlaneAuthPolicy: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.controlPlaneAuthPolicy: %v", err)
 		return ""
 	}
 	return controlPlaneAuthPolicy
@@ -264,7 +264,7 @@ func GetNodeProxyConfigControlPlaneAuthPolicy() string {
 func GetNodeProxyConfigDiscoveryAddress() string {
 	discoveryAddress, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "discoveryAddress"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.discoveryAddress: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.discoveryAddress: %v", err)
 		return ""
 	}
 	return discoveryAddress
@@ -275,7 +275,7 @@ func GetNodeProxyConfigDiscoveryAddress() string {
 func GetNodeProxyConfigDrainDuration() string {
 	drainDuration, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "drainDuration"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.drainDuration: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.drainDuration: %v", err)
 		return ""
 	}
 	return drainDuration
@@ -288,7 +288,7 @@ func GetNodeProxyConfigDrainDuration() string {
 func GetNodeProxyConfigExtraStatTags() []string {
 	extraStatTags, err := getPropertyStringSlice([]string{"node", "metadata", "PROXY_CONFIG", "extraStatTags"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.extraStatTags: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.extraStatTags: %v", err)
 		return make([]string, 0)
 	}
 	return extraStatTags
@@ -300,7 +300,7 @@ func GetNodeProxyConfigExtraStatTags() []string {
 func GetNodeProxyConfigHoldApplicationUntilProxyStarts() bool {
 	holdApplicationUntilProxyStarts, err := getPropertyBool([]string{"node", "metadata", "PROXY_CONFIG", "holdApplicationUntilProxyStarts"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.holdApplicationUntilProxyStarts: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.holdApplicationUntilProxyStarts: %v", err)
 		return false
 	}
 	return holdApplicationUntilProxyStarts
@@ -310,7 +310,7 @@ func GetNodeProxyConfigHoldApplicationUntilProxyStarts() bool {
 func GetNodeProxyConfigProxyAdminPort() int {
 	proxyAdminPortFloat64, err := getPropertyFloat64([]string{"node", "metadata", "PROXY_CONFIG", "proxyAdminPort"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.proxyAdminPort: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.proxyAdminPort: %v", err)
 		return 0
 	}
 	return int(proxyAdminPortFloat64)
@@ -335,17 +335,17 @@ type ProxyStatsMatcher struct {
 func GetNodeProxyConfigProxyStatsMatcher() ProxyStatsMatcher {
 	inclusionPrefixes, err := getPropertyStringSlice([]string{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionPrefixes"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionPrefixes: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionPrefixes: %v", err)
 		inclusionPrefixes = []string{}
 	}
 	inclusionRegexps, err := getPropertyStringSlice([]string{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionRegexps"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionRegexps: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionRegexps: %v", err)
 		inclusionRegexps = []string{}
 	}
 	inclusionSuffixes, err := getPropertyStringSlice([]string{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionSuffixes"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionSuffixes: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.proxyStatsMatcher.inclusionSuffixes: %v", err)
 		inclusionSuffixes = []string{}
 	}
 
@@ -366,7 +366,7 @@ func GetNodeProxyConfigProxyStatsMatcher() ProxyStatsMatcher {
 func GetNodeProxyConfigServiceCluster() string {
 	serviceCluster, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "serviceCluster"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.serviceCluster: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.serviceCluster: %v", err)
 		return ""
 	}
 	return serviceCluster
@@ -380,7 +380,7 @@ func GetNodeProxyConfigServiceCluster() string {
 func GetNodeProxyConfigStatNameLength() int {
 	statNameLength, err := getPropertyFloat64([]string{"node", "metadata", "PROXY_CONFIG", "statNameLength"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.statNameLength: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.statNameLength: %v", err)
 		return 0
 	}
 	return int(statNameLength)
@@ -391,7 +391,7 @@ func GetNodeProxyConfigStatNameLength() int {
 func GetNodeProxyConfigStatusPort() int {
 	statusPort, err := getPropertyFloat64([]string{"node", "metadata", "PROXY_CONFIG", "statusPort"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.statusPort: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.statusPort: %v", err)
 		return 0
 	}
 	return int(statusPort)
@@ -404,7 +404,7 @@ func GetNodeProxyConfigStatusPort() int {
 func GetNodeProxyConfigTerminationDrainDuration() string {
 	terminationDrainDuration, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "terminationDrainDuration"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.terminationDrainDuration: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.terminationDrainDuration: %v", err)
 		return ""
 	}
 	return terminationDrainDuration
@@ -414,7 +414,7 @@ func GetNodeProxyConfigTerminationDrainDuration() string {
 func GetNodeProxyConfigTracingDatadogAddress() string {
 	tracingDatadogAddress, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "tracing", "datadog", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.datadog.address: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.datadog.address: %v", err)
 		return ""
 	}
 	return tracingDatadogAddress
@@ -422,9 +422,9 @@ func GetNodeProxyConfigTracingDatadogAddress() string {
 
 // Get gRPC address for the OpenCensus agent (e.g. dns://authority/host:port or unix:path)
 func GetNodeProxyConfigTracingOpenCensusAgentAddress() string {
-	tracingOpenCensusAgentAddress, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "tracing", "opencensusagent", "address"})
+	tracingOpenCensusAgentAddress, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "tracing", "openCensusAgent", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.opencensusagent.address: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.openCensusAgent.address: %v", err)
 		return ""
 	}
 	return tracingOpenCensusAgentAddress
@@ -434,8 +434,214 @@ func GetNodeProxyConfigTracingOpenCensusAgentAddress() string {
 func GetNodeProxyConfigTracingZipkinAddress() string {
 	tracingZipkinAddress, err := getPropertyString([]string{"node", "metadata", "PROXY_CONFIG", "tracing", "zipkin", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.zipkin.address: %v", err)
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG.tracing.zipkin.address: %v", err)
 		return ""
 	}
 	return tracingZipkinAddress
 }
+
+// Tracing settings of the proxy. Only the address of the configured tracer is set
+//
+// https://istio.io/latest/docs/reference/config/istio.mesh.v1alpha1/#Tracing
+type ProxyConfigTracing struct {
+	SampleRate             float64
+	MaxPathTagLength       int
+	ZipkinAddress          string
+	DatadogAddress         string
+	LightstepAddress       string
+	OpenCensusAgentAddress string
+}
+
+// Typed representation of the istio ProxyConfig passed to the proxy as node metadata
+//
+// https://istio.io/latest/docs/reference/config/istio.mesh.v1alpha1/#ProxyConfig
+type ProxyConfig struct {
+	BinaryPath                      string
+	Concurrency                     int
+	ConfigPath                      string
+	ControlPlaneAuthPolicy          string
+	DiscoveryAddress                string
+	DrainDuration                   time.Duration
+	ExtraStatTags                   []string
+	HoldApplicationUntilProxyStarts bool
+	ProxyAdminPort                  int
+	ProxyMetadata                   map[string]string
+	ProxyStatsMatcher               ProxyStatsMatcher
+	ServiceCluster                  string
+	StatNameLength                  int
+	StatusPort                      int
+	TerminationDrainDuration        time.Duration
+	Tracing                         ProxyConfigTracing
+}
+
+// Get the complete proxy config in a single host call, as opposed to the individual
+// GetNodeProxyConfig* functions which each read a single field
+func GetNodeProxyConfig() ProxyConfig {
+	proxyConfig, err := getPropertyByteSliceMap([]string{"node", "metadata", "PROXY_CONFIG"})
+	if err != nil {
+		logPropertyWarnf("failed reading node.metadata.PROXY_CONFIG: %v", err)
+		return ProxyConfig{}
+	}
+	return deserializeProxyConfig(proxyConfig)
+}
+
+// Helper function to decode the fields of the PROXY_CONFIG struct. Fields that are absent
+// or fail to decode keep their zero value
+//
+// Example envoy extract:
+//
+//	"PROXY_CONFIG": {
+//		"binaryPath": "/usr/local/bin/envoy",
+//		"concurrency": 2,
+//		"configPath": "./etc/istio/proxy",
+//		"controlPlaneAuthPolicy": "MUTUAL_TLS",
+//		"discoveryAddress": "istiod.istio-system.svc:15012",
+//		"drainDuration": "45s",
+//		"proxyAdminPort": 15000,
+//		"serviceCluster": "istio-proxy",
+//		"statNameLength": 189,
+//		"statusPort": 15020,
+//		"terminationDrainDuration": "5s",
+//		"tracing": {
+//			"zipkin": {
+//				"address": "zipkin.istio-system:9411"
+//			}
+//		}
+//	}
+func deserializeProxyConfig(fields map[string][]byte) ProxyConfig {
+	result := ProxyConfig{
+		ExtraStatTags: make([]string, 0),
+		ProxyMetadata: make(map[string]string),
+		ProxyStatsMatcher: ProxyStatsMatcher{
+			InclusionPrefixes: make([]string, 0),
+			InclusionRegexps:  make([]string, 0),
+			InclusionSuffixes: make([]string, 0),
+		},
+	}
+
+	stringFields := []struct {
+		name  string
+		value *string
+	}{
+		{"binaryPath", &result.BinaryPath},
+		{"configPath", &result.ConfigPath},
+		{"controlPlaneAuthPolicy", &result.ControlPlaneAuthPolicy},
+		{"discoveryAddress", &result.DiscoveryAddress},
+		{"serviceCluster", &result.ServiceCluster},
+	}
+	for _, field := range stringFields {
+		if raw, ok := fields[field.name]; ok {
+			*field.value = string(raw)
+		}
+	}
+
+	intFields := []struct {
+		name  string
+		value *int
+	}{
+		{"concurrency", &result.Concurrency},
+		{"proxyAdminPort", &result.ProxyAdminPort},
+		{"statNameLength", &result.StatNameLength},
+		{"statusPort", &result.StatusPort},
+	}
+	for _, field := range intFields {
+		if raw, ok := fields[field.name]; ok {
+			number, err := deserializeToNumber(raw)
+			if err != nil {
+				logPropertyWarnf("failed decoding node.metadata.PROXY_CONFIG.%v: %v", field.name, err)
+				continue
+			}
+			*field.value = int(number)
+		}
+	}
+
+	durationFields := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"drainDuration", &result.DrainDuration},
+		{"terminationDrainDuration", &result.TerminationDrainDuration},
+	}
+	for _, field := range durationFields {
+		if raw, ok := fields[field.name]; ok {
+			duration, err := time.ParseDuration(string(raw))
+			if err != nil {
+				logPropertyWarnf("failed decoding node.metadata.PROXY_CONFIG.%v: %v", field.name, err)
+				continue
+			}
+			*field.value = duration
+		}
+	}
+
+	if raw, ok := fields["holdApplicationUntilProxyStarts"]; ok {
+		hold, err := deserializeToBool(raw)
+		if err != nil {
+			logPropertyWarnf("failed decoding node.metadata.PROXY_CONFIG.holdApplicationUntilProxyStarts: %v", err)
+		}
+		result.HoldApplicationUntilProxyStarts = hold
+	}
+
+	if raw, ok := fields["extraStatTags"]; ok {
+		result.ExtraStatTags = deserializeToStringSlice(raw)
+	}
+
+	if raw, ok := fields["proxyMetadata"]; ok {
+		result.ProxyMetadata = deserializeToStringMap(raw)
+	}
+
+	if raw, ok := fields["proxyStatsMatcher"]; ok {
+		proxyStatsMatcher := deserializeToByteMap(raw)
+		if inclusionPrefixes, ok := proxyStatsMatcher["inclusionPrefixes"]; ok {
+			result.ProxyStatsMatcher.InclusionPrefixes = deserializeToStringSlice(inclusionPrefixes)
+		}
+		if inclusionRegexps, ok := proxyStatsMatcher["inclusionRegexps"]; ok {
+			result.ProxyStatsMatcher.InclusionRegexps = deserializeToStringSlice(inclusionRegexps)
+		}
+		if inclusionSuffixes, ok := proxyStatsMatcher["inclusionSuffixes"]; ok {
+			result.ProxyStatsMatcher.InclusionSuffixes = deserializeToStringSlice(inclusionSuffixes)
+		}
+	}
+
+	if raw, ok := fields["tracing"]; ok {
+		result.Tracing = deserializeProxyConfigTracing(deserializeToByteMap(raw))
+	}
+
+	return result
+}
+
+// Helper function to decode the tracing section of the PROXY_CONFIG struct
+func deserializeProxyConfigTracing(fields map[string][]byte) ProxyConfigTracing {
+	result := ProxyConfigTracing{}
+
+	if raw, ok := fields["sampling"]; ok {
+		sampling, err := deserializeToNumber(raw)
+		if err != nil {
+			logPropertyWarnf("failed decoding node.metadata.PROXY_CONFIG.tracing.sampling: %v", err)
+		}
+		result.SampleRate = sampling
+	}
+	if raw, ok := fields["maxPathTagLength"]; ok {
+		maxPathTagLength, err := deserializeToNumber(raw)
+		if err != nil {
+			logPropertyWarnf("failed decoding node.metadata.PROXY_CONFIG.tracing.maxPathTagLength: %v", err)
+		}
+		result.MaxPathTagLength = int(maxPathTagLength)
+	}
+
+	tracers := []struct {
+		name  string
+		value *string
+	}{
+		{"zipkin", &result.ZipkinAddress},
+		{"datadog", &result.DatadogAddress},
+		{"lightstep", &result.LightstepAddress},
+		{"openCensusAgent", &result.OpenCensusAgentAddress},
+	}
+	for _, tracer := range tracers {
+		if raw, ok := fields[tracer.name]; ok {
+			*tracer.value = string(deserializeToByteMap(raw)["address"])
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,92 @@
+// Helper function to parse the request query string
+// https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#request-attributes
+package properties
+
+import (
+	"strings"
+)
+
+// Query parameters indexed by name, values are kept in the order they appear in the query
+// string. Modelled after url.Values, without pulling net/url into the wasm binary
+type QueryParams map[string][]string
+
+// Get the first value of a query parameter, or an empty string if the parameter is not present
+func (q QueryParams) Get(name string) string {
+	if values := q[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Get all values of a query parameter
+func (q QueryParams) Values(name string) []string {
+	if values, ok := q[name]; ok {
+		return values
+	}
+	return make([]string, 0)
+}
+
+// Check whether a query parameter is present, with or without a value
+func (q QueryParams) Has(name string) bool {
+	_, ok := q[name]
+	return ok
+}
+
+// Get the query parameters of the request (cfr GetRequestQuery())
+func GetRequestQueryParams() QueryParams {
+	return ParseQuery(GetRequestQuery())
+}
+
+// Parse a query string in the format of "name1=value1&name2=value2". Names and values are
+// percent-decoded and "+" is decoded as a space. Invalid escape sequences are kept as is
+func ParseQuery(query string) QueryParams {
+	result := make(QueryParams)
+	for _, pair := range strings.Split(strings.TrimPrefix(query, "?"), "&") {
+		if pair == "" {
+			continue
+		}
+		name, value := pair, ""
+		if i := strings.Index(pair, "="); i >= 0 {
+			name, value = pair[:i], pair[i+1:]
+		}
+		name = unescapeQueryComponent(name)
+		result[name] = append(result[name], unescapeQueryComponent(value))
+	}
+	return result
+}
+
+// Percent-decode a query string component, keeping invalid escape sequences as is
+func unescapeQueryComponent(s string) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '+':
+			b.WriteByte(' ')
+		case s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -2,17 +2,13 @@
 // https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#request-attributes
 package properties
 
-import (
-	"time"
-
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
+import "time"
 
 // Get the path portion of the URL
 func GetRequestPath() string {
 	requestPath, err := getPropertyString([]string{"request", "path"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.path: %v", err)
+		logPropertyWarnf("failed reading request attribute request.path: %v", err)
 		return ""
 	}
 	return requestPath
@@ -22,7 +18,7 @@ func GetRequestPath() string {
 func GetRequestUrlPath() string {
 	requestUrlPath, err := getPropertyString([]string{"request", "url_path"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.url_path: %v", err)
+		logPropertyWarnf("failed reading request attribute request.url_path: %v", err)
 		return ""
 	}
 	return requestUrlPath
@@ -32,7 +28,7 @@ func GetRequestUrlPath() string {
 func GetRequestHost() string {
 	requestHost, err := getPropertyString([]string{"request", "host"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.host: %v", err)
+		logPropertyWarnf("failed reading request attribute request.host: %v", err)
 		return ""
 	}
 	return requestHost
@@ -42,7 +38,7 @@ func GetRequestHost() string {
 func GetRequestScheme() string {
 	requestScheme, err := getPropertyString([]string{"request", "scheme"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.scheme: %v", err)
+		logPropertyWarnf("failed reading request attribute request.scheme: %v", err)
 		return ""
 	}
 	return requestScheme
@@ -52,7 +48,7 @@ func GetRequestScheme() string {
 func GetRequestMethod() string {
 	requestMethod, err := getPropertyString([]string{"request", "method"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.method: %v", err)
+		logPropertyWarnf("failed reading request attribute request.method: %v", err)
 		return ""
 	}
 	return requestMethod
@@ -62,7 +58,7 @@ func GetRequestMethod() string {
 func GetRequestHeaders() map[string]string {
 	requestHeaders, err := getPropertyStringMap([]string{"request", "headers"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.headers: %v", err)
+		logPropertyWarnf("failed reading request attribute request.headers: %v", err)
 		return map[string]string{}
 	}
 	return requestHeaders
@@ -72,7 +68,7 @@ func GetRequestHeaders() map[string]string {
 func GetRequestReferer() string {
 	requestReferer, err := getPropertyString([]string{"request", "referer"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.referer: %v", err)
+		logPropertyWarnf("failed reading request attribute request.referer: %v", err)
 		return ""
 	}
 	return requestReferer
@@ -82,7 +78,7 @@ func GetRequestReferer() string {
 func GetRequestUserAgent() string {
 	requestUserAgent, err := getPropertyString([]string{"request", "useragent"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.useragent: %v", err)
+		logPropertyWarnf("failed reading request attribute request.useragent: %v", err)
 		return ""
 	}
 	return requestUserAgent
@@ -92,7 +88,7 @@ func GetRequestUserAgent() string {
 func GetRequestTime() time.Time {
 	requestTime, err := getPropertTimestamp([]string{"request", "time"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.time: %v", err)
+		logPropertyWarnf("failed reading request attribute request.time: %v", err)
 		return time.Now()
 	}
 	return requestTime
@@ -102,7 +98,7 @@ func GetRequestTime() time.Time {
 func GetRequestId() string {
 	requestId, err := getPropertyString([]string{"request", "id"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.id: %v", err)
+		logPropertyWarnf("failed reading request attribute request.id: %v", err)
 		return ""
 	}
 	return requestId
@@ -112,7 +108,7 @@ func GetRequestId() string {
 func GetRequestProtocol() string {
 	requestProtocol, err := getPropertyString([]string{"request", "protocol"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.protocol: %v", err)
+		logPropertyWarnf("failed reading request attribute request.protocol: %v", err)
 		return ""
 	}
 	return requestProtocol
@@ -122,27 +118,27 @@ func GetRequestProtocol() string {
 func GetRequestQuery() string {
 	requestQuery, err := getPropertyString([]string{"request", "query"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.query: %v", err)
+		logPropertyWarnf("failed reading request attribute request.query: %v", err)
 		return ""
 	}
 	return requestQuery
 }
 
 // Get the total duration of the request, approximated to nano-seconds
-func GetRequestDuration() int {
-	requestDuration, err := getPropertyUint64([]string{"request", "duration"})
+func GetRequestDuration() time.Duration {
+	requestDuration, err := getPropertyDuration([]string{"request", "duration"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.duration: %v", err)
+		logPropertyWarnf("failed reading request attribute request.duration: %v", err)
 		return 0
 	}
-	return int(requestDuration)
+	return requestDuration
 }
 
 // Get the size of the request body. Content length header is used if available
 func GetRequestSize() int {
 	requestSize, err := getPropertyUint64([]string{"request", "size"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.size: %v", err)
+		logPropertyWarnf("failed reading request attribute request.size: %v", err)
 		return 0
 	}
 	return int(requestSize)
@@ -152,7 +148,7 @@ func GetRequestSize() int {
 func GetRequestTotalSize() int {
 	requestTotalSize, err := getPropertyUint64([]string{"request", "total_size"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading request attribute request.total_size: %v", err)
+		logPropertyWarnf("failed reading request attribute request.total_size: %v", err)
 		return 0
 	}
 	return int(requestTotalSize)
@@ -2,15 +2,13 @@
 // https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#response-attributes
 package properties
 
-import (
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
+import "time"
 
 // Get response HTTP status code
 func GetResponseCode() int {
 	responseCode, err := getPropertyUint64([]string{"response", "code"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.code: %v", err)
+		logPropertyWarnf("failed reading response attribute response.code: %v", err)
 		return 0
 	}
 	return int(responseCode)
@@ -20,27 +18,27 @@ func GetResponseCode() int {
 func GetResponseCodeDetails() string {
 	responseCodeDetails, err := getPropertyString([]string{"response", "code_details"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.code_details: %v", err)
+		logPropertyWarnf("failed reading response attribute response.code_details: %v", err)
 		return ""
 	}
 	return responseCodeDetails
 }
 
 // Get additional details about the response beyond the standard response code encoded as a bit-vector
-func GetResponseFlags() int {
+func GetResponseFlags() ResponseFlags {
 	responseFlags, err := getPropertyUint64([]string{"response", "flags"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.flags: %v", err)
+		logPropertyWarnf("failed reading response attribute response.flags: %v", err)
 		return 0
 	}
-	return int(responseFlags)
+	return ResponseFlags(responseFlags)
 }
 
 // Get response gRPC status code
 func GetResponseGrpcStatusCode() int {
 	responseGrpcStatusCode, err := getPropertyUint64([]string{"response", "grpc_status"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.grpc_status: %v", err)
+		logPropertyWarnf("failed reading response attribute response.grpc_status: %v", err)
 		return 0
 	}
 	return int(responseGrpcStatusCode)
@@ -50,7 +48,7 @@ func GetResponseGrpcStatusCode() int {
 func GetResponseHeaders() map[string]string {
 	responseHeaders, err := getPropertyStringMap([]string{"response", "headers"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.headers: %v", err)
+		logPropertyWarnf("failed reading response attribute response.headers: %v", err)
 		return map[string]string{}
 	}
 	return responseHeaders
@@ -60,7 +58,7 @@ func GetResponseHeaders() map[string]string {
 func GetResponseTrailers() map[string]string {
 	responseTrailers, err := getPropertyStringMap([]string{"response", "trailers"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.trailers: %v", err)
+		logPropertyWarnf("failed reading response attribute response.trailers: %v", err)
 		return map[string]string{}
 	}
 	return responseTrailers
@@ -70,7 +68,7 @@ func GetResponseTrailers() map[string]string {
 func GetResponseSize() int {
 	responseSize, err := getPropertyUint64([]string{"response", "size"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.size: %v", err)
+		logPropertyWarnf("failed reading response attribute response.size: %v", err)
 		return 0
 	}
 	return int(responseSize)
@@ -80,8 +78,19 @@ func GetResponseSize() int {
 func GetResponseTotalSize() int {
 	responseTotalSize, err := getPropertyUint64([]string{"response", "total_size"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading response attribute response.total_size: %v", err)
+		logPropertyWarnf("failed reading response attribute response.total_size: %v", err)
 		return 0
 	}
 	return int(responseTotalSize)
 }
+
+// Get the duration between the first byte sent upstream and the first byte received from the
+// upstream, approximated to nano-seconds
+func GetResponseBackendLatency() time.Duration {
+	responseBackendLatency, err := getPropertyDuration([]string{"response", "backend_latency"})
+	if err != nil {
+		logPropertyWarnf("failed reading response attribute response.backend_latency: %v", err)
+		return 0
+	}
+	return responseBackendLatency
+}
@@ -0,0 +1,128 @@
+// Helper type to decode the response flags bit-vector
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/observability/access_log/usage#config-access-log-format-response-flags
+package properties
+
+import "strings"
+
+// Additional details about the response or connection beyond the standard response code,
+// encoded as a bit-vector by envoy
+type ResponseFlags uint64
+
+const (
+	FailedLocalHealthCheck           ResponseFlags = 1 << iota // LH
+	NoHealthyUpstream                                          // UH
+	UpstreamRequestTimeout                                     // UT
+	LocalReset                                                 // LR
+	UpstreamRemoteReset                                        // UR
+	UpstreamConnectionFailure                                  // UF
+	UpstreamConnectionTermination                              // UC
+	UpstreamOverflow                                           // UO
+	NoRouteFound                                               // NR
+	DelayInjected                                              // DI
+	FaultInjected                                              // FI
+	RateLimited                                                // RL
+	UnauthorizedExternalService                                // UAEX
+	RateLimitServiceError                                      // RLSE
+	DownstreamConnectionTermination                            // DC
+	UpstreamRetryLimitExceeded                                 // URX
+	StreamIdleTimeout                                          // SI
+	InvalidEnvoyRequestHeaders                                 // IH
+	DownstreamProtocolError                                    // DPE
+	UpstreamMaxStreamDurationReached                           // UMSDR
+	ResponseFromCacheFilter                                    // RFCF
+	NoFilterConfigFound                                        // NFCF
+	DurationTimeout                                            // DT
+	UpstreamProtocolError                                      // UPE
+	NoClusterFound                                             // NC
+	OverloadManager                                            // OM
+	DnsResolutionFailed                                        // DF
+	DropOverLoad                                               // DO
+	DownstreamRemoteReset                                      // DR
+)
+
+// Envoy short codes of the response flags, in bit order
+var responseFlagShortCodes = []struct {
+	flag ResponseFlags
+	code string
+}{
+	{FailedLocalHealthCheck, "LH"},
+	{NoHealthyUpstream, "UH"},
+	{UpstreamRequestTimeout, "UT"},
+	{LocalReset, "LR"},
+	{UpstreamRemoteReset, "UR"},
+	{UpstreamConnectionFailure, "UF"},
+	{UpstreamConnectionTermination, "UC"},
+	{UpstreamOverflow, "UO"},
+	{NoRouteFound, "NR"},
+	{DelayInjected, "DI"},
+	{FaultInjected, "FI"},
+	{RateLimited, "RL"},
+	{UnauthorizedExternalService, "UAEX"},
+	{RateLimitServiceError, "RLSE"},
+	{DownstreamConnectionTermination, "DC"},
+	{UpstreamRetryLimitExceeded, "URX"},
+	{StreamIdleTimeout, "SI"},
+	{InvalidEnvoyRequestHeaders, "IH"},
+	{DownstreamProtocolError, "DPE"},
+	{UpstreamMaxStreamDurationReached, "UMSDR"},
+	{ResponseFromCacheFilter, "RFCF"},
+	{NoFilterConfigFound, "NFCF"},
+	{DurationTimeout, "DT"},
+	{UpstreamProtocolError, "UPE"},
+	{NoClusterFound, "NC"},
+	{OverloadManager, "OM"},
+	{DnsResolutionFailed, "DF"},
+	{DropOverLoad, "DO"},
+	{DownstreamRemoteReset, "DR"},
+}
+
+// Flags that indicate the request failed because of the upstream
+const upstreamFailureFlags = NoHealthyUpstream | UpstreamRequestTimeout | UpstreamRemoteReset |
+	UpstreamConnectionFailure | UpstreamConnectionTermination | UpstreamOverflow |
+	UpstreamRetryLimitExceeded | UpstreamMaxStreamDurationReached | UpstreamProtocolError
+
+// Flags that indicate the request failed because of the downstream
+const downstreamFailureFlags = DownstreamConnectionTermination | DownstreamProtocolError | DownstreamRemoteReset
+
+// String returns the flags in envoy access log short code form (e.g. "UF,URX"), or "-" if
+// no flag is set
+func (f ResponseFlags) String() string {
+	codes := make([]string, 0)
+	for _, shortCode := range responseFlagShortCodes {
+		if f&shortCode.flag != 0 {
+			codes = append(codes, shortCode.code)
+		}
+	}
+	if len(codes) == 0 {
+		return "-"
+	}
+	return strings.Join(codes, ",")
+}
+
+// Has returns true if all given flags are set
+func (f ResponseFlags) Has(flags ResponseFlags) bool {
+	return f&flags == flags
+}
+
+// HasUpstreamFailure returns true if any flag indicating an upstream failure is set (UH, UT,
+// UR, UF, UC, UO, URX, UMSDR or UPE)
+func (f ResponseFlags) HasUpstreamFailure() bool {
+	return f&upstreamFailureFlags != 0
+}
+
+// HasDownstreamFailure returns true if any flag indicating a downstream failure is set (DC,
+// DPE or DR)
+func (f ResponseFlags) HasDownstreamFailure() bool {
+	return f&downstreamFailureFlags != 0
+}
+
+// IsRateLimited returns true if the request was rate limited locally or by the rate limit
+// service (RL or RLSE)
+func (f ResponseFlags) IsRateLimited() bool {
+	return f&(RateLimited|RateLimitServiceError) != 0
+}
+
+// IsFaultInjected returns true if a delay or abort fault was injected (DI or FI)
+func (f ResponseFlags) IsFaultInjected() bool {
+	return f&(DelayInjected|FaultInjected) != 0
+}
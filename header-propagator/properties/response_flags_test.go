@@ -0,0 +1,90 @@
+package properties
+
+import "testing"
+
+// Bit positions of the response flags as defined by envoy in StreamInfo::ResponseFlag
+// https://github.com/envoyproxy/envoy/blob/main/envoy/stream_info/stream_info.h
+var envoyResponseFlagBits = []string{
+	"LH", "UH", "UT", "LR", "UR", "UF", "UC", "UO", "NR", "DI", "FI", "RL", "UAEX", "RLSE",
+	"DC", "URX", "SI", "IH", "DPE", "UMSDR", "RFCF", "NFCF", "DT", "UPE", "NC", "OM", "DF",
+	"DO", "DR",
+}
+
+func TestResponseFlagsBits(t *testing.T) {
+	for bit, code := range envoyResponseFlagBits {
+		flag := ResponseFlags(1) << bit
+		if got := flag.String(); got != code {
+			t.Errorf("bit %d: got %q, want %q", bit, got, code)
+		}
+		if !flag.Has(flag) {
+			t.Errorf("bit %d: expected Has(%v)", bit, code)
+		}
+	}
+
+	unknown := ResponseFlags(1) << len(envoyResponseFlagBits)
+	if got := unknown.String(); got != "-" {
+		t.Errorf("expected unknown bits to be ignored, got %q", got)
+	}
+}
+
+func TestResponseFlagsString(t *testing.T) {
+	tests := []struct {
+		flags ResponseFlags
+		want  string
+	}{
+		{0, "-"},
+		{UpstreamConnectionFailure | UpstreamRetryLimitExceeded, "UF,URX"},
+		{DownstreamRemoteReset | FailedLocalHealthCheck, "LH,DR"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.flags.String(); got != tt.want {
+			t.Errorf("ResponseFlags(%d).String(): got %q, want %q", uint64(tt.flags), got, tt.want)
+		}
+	}
+}
+
+func TestResponseFlagsPredicates(t *testing.T) {
+	tests := []struct {
+		name           string
+		flags          ResponseFlags
+		has            ResponseFlags
+		wantHas        bool
+		wantUpstream   bool
+		wantDownstream bool
+		wantRateLimit  bool
+		wantFault      bool
+	}{
+		{"none", 0, NoRouteFound, false, false, false, false, false},
+		{"no route", NoRouteFound, NoRouteFound, true, false, false, false, false},
+		{"upstream failure", UpstreamConnectionFailure | UpstreamRetryLimitExceeded, UpstreamConnectionFailure | UpstreamRetryLimitExceeded, true, true, false, false, false},
+		{"has needs all flags", UpstreamConnectionFailure, UpstreamConnectionFailure | UpstreamRetryLimitExceeded, false, true, false, false, false},
+		{"upstream protocol error", UpstreamProtocolError, UpstreamProtocolError, true, true, false, false, false},
+		{"downstream reset", DownstreamRemoteReset, DownstreamRemoteReset, true, false, true, false, false},
+		{"downstream protocol error", DownstreamProtocolError, DownstreamProtocolError, true, false, true, false, false},
+		{"rate limited", RateLimited, RateLimited, true, false, false, true, false},
+		{"rate limit service error", RateLimitServiceError, RateLimitServiceError, true, false, false, true, false},
+		{"delay injected", DelayInjected, DelayInjected, true, false, false, false, true},
+		{"abort injected", FaultInjected | UpstreamRequestTimeout, FaultInjected, true, true, false, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.flags.Has(tt.has); got != tt.wantHas {
+				t.Errorf("Has(%v): got %v", tt.has, got)
+			}
+			if got := tt.flags.HasUpstreamFailure(); got != tt.wantUpstream {
+				t.Errorf("HasUpstreamFailure(): got %v", got)
+			}
+			if got := tt.flags.HasDownstreamFailure(); got != tt.wantDownstream {
+				t.Errorf("HasDownstreamFailure(): got %v", got)
+			}
+			if got := tt.flags.IsRateLimited(); got != tt.wantRateLimit {
+				t.Errorf("IsRateLimited(): got %v", got)
+			}
+			if got := tt.flags.IsFaultInjected(); got != tt.wantFault {
+				t.Errorf("IsFaultInjected(): got %v", got)
+			}
+		})
+	}
+}
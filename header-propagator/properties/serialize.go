@@ -0,0 +1,126 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+	"time"
+)
+
+// The serialize functions are the inverse of the deserialize functions in utils.go. They
+// produce property values encoded the way the host does, so plugins can be tested against
+// realistic values (e.g. with proxytest.HostEmulator.SetProperty or LoadFixture). Like
+// LoadFixture they are only built with the proxytest tag, keeping them out of the plugins
+
+// Serialize a uint64, inverse of deserializeToUint64
+func SerializeUint64(value uint64) []byte {
+	bs := make([]byte, 8)
+	binary.LittleEndian.PutUint64(bs, value)
+	return bs
+}
+
+// Serialize a float64, inverse of deserializeToFloat64
+func SerializeFloat64(value float64) []byte {
+	return SerializeUint64(math.Float64bits(value))
+}
+
+// Serialize a bool as a single byte, inverse of deserializeToBool
+func SerializeBool(value bool) []byte {
+	if value {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// Serialize a timestamp as nanoseconds since epoch, inverse of deserializeToTimestamp
+func SerializeTimestamp(value time.Time) []byte {
+	return SerializeUint64(uint64(value.UnixNano()))
+}
+
+// Serialize a duration as nanoseconds, inverse of deserializeToDuration
+func SerializeDuration(value time.Duration) []byte {
+	return SerializeUint64(uint64(value.Nanoseconds()))
+}
+
+// Serialize a string slice, inverse of deserializeToStringSlice
+func SerializeStringSlice(values []string) []byte {
+	bss := make([][]byte, len(values))
+	for i, value := range values {
+		bss[i] = []byte(value)
+	}
+	return SerializeByteSliceSlice(bss)
+}
+
+// Serialize a slice of byte slices, inverse of deserializeToByteSliceSlice
+//   - 4 bytes with the number of elements
+//   - 8 bytes with the size of every element
+//   - the elements, each followed by 2 null bytes
+func SerializeByteSliceSlice(values [][]byte) []byte {
+	size := 4 + 8*len(values)
+	for _, value := range values {
+		size += len(value) + 2
+	}
+	bs := make([]byte, size)
+	binary.LittleEndian.PutUint32(bs[0:4], uint32(len(values)))
+	sizeIndex := 4
+	dataIndex := 4 + 8*len(values)
+	for _, value := range values {
+		binary.LittleEndian.PutUint64(bs[sizeIndex:sizeIndex+8], uint64(len(value)))
+		sizeIndex += 8
+		dataIndex += copy(bs[dataIndex:], value) + 2
+	}
+	return bs
+}
+
+// Serialize a protobuf string slice (e.g. node.client_features), inverse of
+// deserializeProtobufToStringSlice. Every string is encoded as a length delimited field of
+// field number 1, with the length as varint
+func SerializeProtobufStringSlice(values []string) []byte {
+	bs := make([]byte, 0)
+	for _, value := range values {
+		bs = append(bs, 0x0a)
+		bs = binary.AppendUvarint(bs, uint64(len(value)))
+		bs = append(bs, value...)
+	}
+	return bs
+}
+
+// Serialize a string map, inverse of deserializeToStringMap
+func SerializeStringMap(m map[string]string) []byte {
+	byteMap := make(map[string][]byte, len(m))
+	for key, value := range m {
+		byteMap[key] = []byte(value)
+	}
+	return SerializeByteMap(byteMap)
+}
+
+// Serialize a map of byte slices (e.g. a protobuf struct with mixed types), inverse of
+// deserializeToByteMap. Keys are written in sorted order, so the output is stable
+//   - 4 bytes with the number of entries
+//   - 4 bytes with the key size and 4 bytes with the value size of every entry
+//   - the keys and values, each followed by a null byte
+func SerializeByteMap(m map[string][]byte) []byte {
+	keys := make([]string, 0, len(m))
+	size := 4
+	for key, value := range m {
+		keys = append(keys, key)
+		size += 8 + len(key) + len(value) + 2
+	}
+	sort.Strings(keys)
+
+	bs := make([]byte, size)
+	binary.LittleEndian.PutUint32(bs[0:4], uint32(len(m)))
+	sizeIndex := 4
+	dataIndex := 4 + 8*len(m)
+	for _, key := range keys {
+		value := m[key]
+		binary.LittleEndian.PutUint32(bs[sizeIndex:sizeIndex+4], uint32(len(key)))
+		binary.LittleEndian.PutUint32(bs[sizeIndex+4:sizeIndex+8], uint32(len(value)))
+		sizeIndex += 8
+		dataIndex += copy(bs[dataIndex:], key) + 1
+		dataIndex += copy(bs[dataIndex:], value) + 1
+	}
+	return bs
+}
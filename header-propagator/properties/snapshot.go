@@ -0,0 +1,181 @@
+// Helper struct to memoize property lookups during the lifetime of an http context
+package properties
+
+import "time"
+
+// Snapshot lazily fetches and caches properties, so every attribute crosses the host boundary
+// (and gets decoded) at most once per phase. A snapshot is meant to be owned by a single
+// HttpContext, created in NewHttpContext and advanced with NextPhase at the start of every
+// callback. Attributes that cannot change during the lifetime of a stream (e.g. listener
+// direction) survive NextPhase, all others are fetched again when accessed in a later phase
+//
+// Header getters reflect the headers at the time of the first access, call
+// InvalidateRequestHeaders or InvalidateResponseHeaders after mutating headers
+type Snapshot struct {
+	values map[string]interface{}
+	stable map[string]bool
+}
+
+// Create a new empty snapshot
+func NewSnapshot() *Snapshot {
+	return &Snapshot{
+		values: make(map[string]interface{}),
+		stable: make(map[string]bool),
+	}
+}
+
+// Drop all cached attributes that can change between phases
+func (s *Snapshot) NextPhase() {
+	for key := range s.values {
+		if !s.stable[key] {
+			delete(s.values, key)
+		}
+	}
+}
+
+// Drop all cached attributes, including the ones marked stable
+func (s *Snapshot) Invalidate() {
+	s.values = make(map[string]interface{})
+	s.stable = make(map[string]bool)
+}
+
+// Drop the cached request headers, to be called after request header mutations
+func (s *Snapshot) InvalidateRequestHeaders() {
+	delete(s.values, "request.headers")
+}
+
+// Drop the cached response headers, to be called after response header mutations
+func (s *Snapshot) InvalidateResponseHeaders() {
+	delete(s.values, "response.headers")
+}
+
+// Get a cached value, calling fetch to populate the cache when the key is not present. Plugins
+// can use this to memoize attributes the snapshot has no dedicated getter for
+func (s *Snapshot) Get(key string, fetch func() interface{}) interface{} {
+	if value, ok := s.values[key]; ok {
+		return value
+	}
+	return s.refetch(key, fetch)
+}
+
+// Fetch a value and replace the cached one, also used by the typed getters when the key was
+// cached with a different type through Get
+func (s *Snapshot) refetch(key string, fetch func() interface{}) interface{} {
+	value := fetch()
+	s.values[key] = value
+	return value
+}
+
+// Same as Get, but the value survives NextPhase and is only fetched once per stream
+func (s *Snapshot) GetStable(key string, fetch func() interface{}) interface{} {
+	s.stable[key] = true
+	return s.Get(key, fetch)
+}
+
+// Get all request headers indexed by the lower-cased header name (cfr GetRequestHeaders())
+func (s *Snapshot) RequestHeaders() map[string]string {
+	fetch := func() interface{} { return GetRequestHeaders() }
+	if value, ok := s.Get("request.headers", fetch).(map[string]string); ok {
+		return value
+	}
+	return s.refetch("request.headers", fetch).(map[string]string)
+}
+
+// Get all response headers indexed by the lower-cased header name (cfr GetResponseHeaders())
+func (s *Snapshot) ResponseHeaders() map[string]string {
+	fetch := func() interface{} { return GetResponseHeaders() }
+	if value, ok := s.Get("response.headers", fetch).(map[string]string); ok {
+		return value
+	}
+	return s.refetch("response.headers", fetch).(map[string]string)
+}
+
+// Get all response trailers indexed by the lower-cased trailer name (cfr GetResponseTrailers())
+func (s *Snapshot) ResponseTrailers() map[string]string {
+	fetch := func() interface{} { return GetResponseTrailers() }
+	if value, ok := s.Get("response.trailers", fetch).(map[string]string); ok {
+		return value
+	}
+	return s.refetch("response.trailers", fetch).(map[string]string)
+}
+
+// Get the path portion of the URL (cfr GetRequestPath())
+func (s *Snapshot) RequestPath() string {
+	fetch := func() interface{} { return GetRequestPath() }
+	if value, ok := s.Get("request.path", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("request.path", fetch).(string)
+}
+
+// Get the host portion of the URL (cfr GetRequestHost())
+func (s *Snapshot) RequestHost() string {
+	fetch := func() interface{} { return GetRequestHost() }
+	if value, ok := s.Get("request.host", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("request.host", fetch).(string)
+}
+
+// Get the request method (cfr GetRequestMethod())
+func (s *Snapshot) RequestMethod() string {
+	fetch := func() interface{} { return GetRequestMethod() }
+	if value, ok := s.Get("request.method", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("request.method", fetch).(string)
+}
+
+// Get the request ID (cfr GetRequestId())
+func (s *Snapshot) RequestId() string {
+	fetch := func() interface{} { return GetRequestId() }
+	if value, ok := s.GetStable("request.id", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("request.id", fetch).(string)
+}
+
+// Get the time of the first byte received (cfr GetRequestTime())
+func (s *Snapshot) RequestTime() time.Time {
+	fetch := func() interface{} { return GetRequestTime() }
+	if value, ok := s.GetStable("request.time", fetch).(time.Time); ok {
+		return value
+	}
+	return s.refetch("request.time", fetch).(time.Time)
+}
+
+// Get response HTTP status code (cfr GetResponseCode())
+func (s *Snapshot) ResponseCode() int {
+	fetch := func() interface{} { return GetResponseCode() }
+	if value, ok := s.Get("response.code", fetch).(int); ok {
+		return value
+	}
+	return s.refetch("response.code", fetch).(int)
+}
+
+// Get listener direction (cfr GetListenerDirection())
+func (s *Snapshot) ListenerDirection() TrafficDirection {
+	fetch := func() interface{} { return GetListenerDirection() }
+	if value, ok := s.GetStable("listener_direction", fetch).(TrafficDirection); ok {
+		return value
+	}
+	return s.refetch("listener_direction", fetch).(TrafficDirection)
+}
+
+// Get upstream cluster name (cfr GetClusterName())
+func (s *Snapshot) ClusterName() string {
+	fetch := func() interface{} { return GetClusterName() }
+	if value, ok := s.Get("cluster_name", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("cluster_name", fetch).(string)
+}
+
+// Get route name (cfr GetRouteName())
+func (s *Snapshot) RouteName() string {
+	fetch := func() interface{} { return GetRouteName() }
+	if value, ok := s.Get("route_name", fetch).(string); ok {
+		return value
+	}
+	return s.refetch("route_name", fetch).(string)
+}
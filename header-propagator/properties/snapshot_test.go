@@ -0,0 +1,139 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// Create a host emulator with the given attributes loaded and the phase reset
+func newSnapshotHost(t *testing.T, fixture string) (proxytest.HostEmulator, func()) {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{})
+	host, reset := proxytest.NewHostEmulator(opt)
+	if err := LoadFixture(host, []byte(fixture)); err != nil {
+		reset()
+		t.Fatal(err)
+	}
+	SetPhase(PhaseUnknown)
+	return host, reset
+}
+
+// Change a string attribute in the emulated proxy
+func setStringProperty(t *testing.T, host proxytest.HostEmulator, path []string, value string) {
+	t.Helper()
+	if err := host.SetProperty(path, []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSnapshotMemoizesPerPhase(t *testing.T) {
+	host, reset := newSnapshotHost(t, `{"request": {"path": "/a", "id": "req-1"}}`)
+	defer reset()
+
+	snapshot := NewSnapshot()
+	if got := snapshot.RequestPath(); got != "/a" {
+		t.Fatalf("request.path: got %q", got)
+	}
+
+	setStringProperty(t, host, []string{"request", "path"}, "/b")
+	setStringProperty(t, host, []string{"request", "id"}, "req-2")
+	if got := snapshot.RequestPath(); got != "/a" {
+		t.Errorf("expected request.path to be fetched once per phase, got %q", got)
+	}
+	if got := snapshot.RequestId(); got != "req-2" {
+		t.Errorf("request.id: got %q", got)
+	}
+
+	snapshot.NextPhase()
+	setStringProperty(t, host, []string{"request", "id"}, "req-3")
+	if got := snapshot.RequestPath(); got != "/b" {
+		t.Errorf("expected request.path to be fetched again in the next phase, got %q", got)
+	}
+	if got := snapshot.RequestId(); got != "req-2" {
+		t.Errorf("expected the stable request.id to survive the next phase, got %q", got)
+	}
+}
+
+func TestSnapshotInvalidate(t *testing.T) {
+	host, reset := newSnapshotHost(t, `{"request": {"path": "/a", "id": "req-1"}}`)
+	defer reset()
+
+	snapshot := NewSnapshot()
+	snapshot.RequestId()
+	setStringProperty(t, host, []string{"request", "id"}, "req-2")
+
+	snapshot.Invalidate()
+	if got := snapshot.RequestId(); got != "req-2" {
+		t.Errorf("expected request.id to be fetched again after Invalidate, got %q", got)
+	}
+
+	// a key marked stable before Invalidate no longer survives NextPhase when fetched with Get
+	snapshot.Invalidate()
+	snapshot.Get("request.id", func() interface{} { return "cached" })
+	snapshot.NextPhase()
+	if got := snapshot.RequestId(); got != "req-2" {
+		t.Errorf("expected Invalidate to drop the stable keys, got %q", got)
+	}
+}
+
+func TestSnapshotInvalidateHeaders(t *testing.T) {
+	host, reset := newSnapshotHost(t, `{
+		"request": {"headers": {":path": "/a", "x-tenant": "acme"}},
+		"response": {"headers": {":status": "200"}}
+	}`)
+	defer reset()
+
+	snapshot := NewSnapshot()
+	if got := snapshot.RequestHeaders()["x-tenant"]; got != "acme" {
+		t.Fatalf("request.headers: got %q", got)
+	}
+	snapshot.ResponseHeaders()
+
+	mutated := map[string]string{":path": "/a", "x-tenant": "globex"}
+	if err := host.SetProperty([]string{"request", "headers"}, SerializeStringMap(mutated)); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.SetProperty([]string{"response", "headers"}, SerializeStringMap(map[string]string{":status": "503"})); err != nil {
+		t.Fatal(err)
+	}
+	if got := snapshot.RequestHeaders()["x-tenant"]; got != "acme" {
+		t.Errorf("expected the request headers of the first access, got %q", got)
+	}
+
+	snapshot.InvalidateRequestHeaders()
+	if got := snapshot.RequestHeaders()["x-tenant"]; got != "globex" {
+		t.Errorf("expected the mutated request headers after InvalidateRequestHeaders, got %q", got)
+	}
+	if got := snapshot.ResponseHeaders()[":status"]; got != "200" {
+		t.Errorf("expected the response headers to stay cached, got %q", got)
+	}
+
+	snapshot.InvalidateResponseHeaders()
+	if got := snapshot.ResponseHeaders()[":status"]; got != "503" {
+		t.Errorf("expected the mutated response headers after InvalidateResponseHeaders, got %q", got)
+	}
+}
+
+// A key cached with another type through Get is fetched again by the typed getter
+func TestSnapshotGetDifferentType(t *testing.T) {
+	_, reset := newSnapshotHost(t, `{"request": {"path": "/a"}}`)
+	defer reset()
+
+	snapshot := NewSnapshot()
+	if got := snapshot.Get("request.path", func() interface{} { return []byte("/raw") }); string(got.([]byte)) != "/raw" {
+		t.Fatalf("expected the fetched value, got %v", got)
+	}
+	if got := snapshot.RequestPath(); got != "/a" {
+		t.Errorf("expected request.path to be fetched again as string, got %q", got)
+	}
+
+	fetched := false
+	snapshot.Get("request.path", func() interface{} { fetched = true; return "" })
+	if fetched {
+		t.Error("expected the refetched value to be cached")
+	}
+}
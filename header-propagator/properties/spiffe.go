@@ -0,0 +1,85 @@
+// Helper function to parse SPIFFE identities from peer certificates
+// https://github.com/spiffe/spiffe/blob/main/standards/SPIFFE-ID.md
+// https://istio.io/latest/docs/concepts/security/#istio-identity
+package properties
+
+import (
+	"fmt"
+	"strings"
+)
+
+const spiffeScheme = "spiffe://"
+
+// Workload identity as encoded by istio in the URI SAN of workload certificates
+//
+// Example value: spiffe://cluster.local/ns/default/sa/httpbin
+type SpiffeIdentity struct {
+	TrustDomain    string
+	Namespace      string
+	ServiceAccount string
+}
+
+// String returns the SPIFFE ID of the identity, or an empty string for the zero value
+func (s SpiffeIdentity) String() string {
+	if s == (SpiffeIdentity{}) {
+		return ""
+	}
+	return fmt.Sprintf("%v%v/ns/%v/sa/%v", spiffeScheme, s.TrustDomain, s.Namespace, s.ServiceAccount)
+}
+
+// Parse a SPIFFE ID in the istio format spiffe://<trust-domain>/ns/<namespace>/sa/<service-account>
+func ParseSpiffeIdentity(spiffeId string) (SpiffeIdentity, error) {
+	if !strings.HasPrefix(spiffeId, spiffeScheme) {
+		return SpiffeIdentity{}, fmt.Errorf("invalid spiffe id %q: missing %v scheme", spiffeId, spiffeScheme)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(spiffeId, spiffeScheme), "/")
+	if len(segments) != 5 || segments[1] != "ns" || segments[3] != "sa" {
+		return SpiffeIdentity{}, fmt.Errorf("invalid spiffe id %q: expected format %v<trust-domain>/ns/<namespace>/sa/<service-account>", spiffeId, spiffeScheme)
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return SpiffeIdentity{}, fmt.Errorf("invalid spiffe id %q: empty path segment", spiffeId)
+		}
+	}
+
+	return SpiffeIdentity{
+		TrustDomain:    segments[0],
+		Namespace:      segments[2],
+		ServiceAccount: segments[4],
+	}, nil
+}
+
+// Get the SPIFFE identity of the peer in the downstream TLS connection
+func GetDownstreamPeerSpiffeIdentity() SpiffeIdentity {
+	return getSpiffeIdentity("connection.uri_san_peer_certificate", GetDownstreamUriSanPeerCertificate())
+}
+
+// Get the SPIFFE identity of the local certificate in the downstream TLS connection
+func GetDownstreamLocalSpiffeIdentity() SpiffeIdentity {
+	return getSpiffeIdentity("connection.uri_san_local_certificate", GetDownstreamUriSanLocalCertificate())
+}
+
+// Get the SPIFFE identity of the peer in the upstream TLS connection
+func GetUpstreamPeerSpiffeIdentity() SpiffeIdentity {
+	return getSpiffeIdentity("upstream.uri_san_peer_certificate", GetUpstreamUriSanPeerCertificate())
+}
+
+// Get the SPIFFE identity of the local certificate in the upstream TLS connection
+func GetUpstreamLocalSpiffeIdentity() SpiffeIdentity {
+	return getSpiffeIdentity("upstream.uri_san_local_certificate", GetUpstreamUriSanLocalCertificate())
+}
+
+// Helper function to parse a URI SAN attribute value, returns the zero value if the
+// attribute is empty or does not hold an istio SPIFFE ID
+func getSpiffeIdentity(attribute string, uriSan string) SpiffeIdentity {
+	if uriSan == "" {
+		return SpiffeIdentity{}
+	}
+	identity, err := ParseSpiffeIdentity(uriSan)
+	if err != nil {
+		logPropertyWarnf("failed parsing attribute %v: %v", attribute, err)
+		return SpiffeIdentity{}
+	}
+	return identity
+}
@@ -0,0 +1,42 @@
+package properties
+
+import "testing"
+
+func TestParseSpiffeIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		spiffeId string
+		want     SpiffeIdentity
+		wantErr  bool
+	}{
+		{"valid", "spiffe://td/ns/x/sa/y", SpiffeIdentity{TrustDomain: "td", Namespace: "x", ServiceAccount: "y"}, false},
+		{"istio default trust domain", "spiffe://cluster.local/ns/default/sa/httpbin", SpiffeIdentity{TrustDomain: "cluster.local", Namespace: "default", ServiceAccount: "httpbin"}, false},
+		{"missing scheme", "td/ns/x/sa/y", SpiffeIdentity{}, true},
+		{"other scheme", "https://td/ns/x/sa/y", SpiffeIdentity{}, true},
+		{"extra path segment", "spiffe://td/ns/x/sa/y/z", SpiffeIdentity{}, true},
+		{"trailing slash", "spiffe://td/ns/x/sa/y/", SpiffeIdentity{}, true},
+		{"missing service account", "spiffe://td/ns/x/sa", SpiffeIdentity{}, true},
+		{"missing namespace segment", "spiffe://td/x/sa/y", SpiffeIdentity{}, true},
+		{"swapped segments", "spiffe://td/sa/y/ns/x", SpiffeIdentity{}, true},
+		{"empty namespace", "spiffe://td/ns//sa/y", SpiffeIdentity{}, true},
+		{"empty trust domain", "spiffe:///ns/x/sa/y", SpiffeIdentity{}, true},
+		{"scheme only", "spiffe://", SpiffeIdentity{}, true},
+		{"empty", "", SpiffeIdentity{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSpiffeIdentity(tt.spiffeId)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("ParseSpiffeIdentity(%q): got %+v (%v), want %+v", tt.spiffeId, got, err, tt.want)
+			}
+			if err == nil && got.String() != tt.spiffeId {
+				t.Errorf("String(): got %q, want %q", got.String(), tt.spiffeId)
+			}
+		})
+	}
+
+	if got := (SpiffeIdentity{}).String(); got != "" {
+		t.Errorf("String() of the zero value: got %q", got)
+	}
+}
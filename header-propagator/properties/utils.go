@@ -2,16 +2,16 @@ package properties
 
 import (
 	"encoding/binary"
+	"fmt"
 	"math"
+	"strconv"
 	"time"
 	"unsafe"
-
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
 )
 
 // Get string property
 func getPropertyString(path []string) (string, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return "", err
 	}
@@ -21,7 +21,7 @@ func getPropertyString(path []string) (string, error) {
 
 // Get uint64 property
 func getPropertyUint64(path []string) (uint64, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return 0, err
 	}
@@ -29,29 +29,29 @@ func getPropertyUint64(path []string) (uint64, error) {
 	return deserializeToUint64(b), nil
 }
 
-// Get float64 property
+// Get float64 property of a protobuf struct, encoded either as float64 or as string
 func getPropertyFloat64(path []string) (float64, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return deserializeToFloat64(b), nil
+	return deserializeToNumber(b)
 }
 
-// Get bool property
+// Get bool property, encoded either as a single byte or as string
 func getPropertyBool(path []string) (bool, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return false, err
 	}
 
-	return b[0] != 0, nil
+	return deserializeToBool(b)
 }
 
 // Get timestamp property
 func getPropertTimestamp(path []string) (time.Time, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return time.Now(), err
 	}
@@ -59,10 +59,20 @@ func getPropertTimestamp(path []string) (time.Time, error) {
 	return deserializeToTimestamp(b), nil
 }
 
+// Get duration property
+func getPropertyDuration(path []string) (time.Duration, error) {
+	b, err := getProperty(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return deserializeToDuration(b), nil
+}
+
 // Get complex property object as a map of byte slices
 // to be used when dealing with mixed type properties
 func getPropertyByteSliceMap(path []string) (map[string][]byte, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +83,7 @@ func getPropertyByteSliceMap(path []string) (map[string][]byte, error) {
 // Get complex property object as a map of string
 // to be used when dealing with string only type properties
 func getPropertyStringMap(path []string) (map[string]string, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +93,7 @@ func getPropertyStringMap(path []string) (map[string]string, error) {
 
 // Get complex property object as a string slice
 func getPropertyStringSlice(path []string) ([]string, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return nil, err
 	}
@@ -108,7 +118,7 @@ func deserializeToStringSlice(bs []byte) []string {
 
 // Get complex property object as a string slice
 func getPropertyByteSliceSlice(path []string) ([][]byte, error) {
-	b, err := proxywasm.GetProperty(path)
+	b, err := getProperty(path)
 	if err != nil {
 		return nil, err
 	}
@@ -149,17 +159,48 @@ func deserializeToTimestamp(data []byte) time.Time {
 	return time.Unix(0, nanos)
 }
 
-// deserialize a protobuf encoded string slice
+// deserialize byte array to duration
+func deserializeToDuration(data []byte) time.Duration {
+	nanos := int64(binary.LittleEndian.Uint64(data))
+	return time.Duration(nanos)
+}
+
+// deserialize a protobuf struct number, which hosts encode either as a float64 or as a
+// string depending on the envoy/istio version
+func deserializeToNumber(data []byte) (float64, error) {
+	if number, err := strconv.ParseFloat(string(data), 64); err == nil {
+		return number, nil
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid number encoding of %d bytes", len(data))
+	}
+	return deserializeToFloat64(data), nil
+}
+
+// deserialize a protobuf struct bool, which hosts encode either as a single byte or as a
+// string depending on the envoy/istio version
+func deserializeToBool(data []byte) (bool, error) {
+	if len(data) == 1 && data[0] <= 1 {
+		return data[0] == 1, nil
+	}
+	return strconv.ParseBool(string(data))
+}
+
+// deserialize a protobuf encoded string slice, every string is a length delimited field with its
+// length encoded as varint. Decoding stops at truncated input
 func deserializeProtobufToStringSlice(data []byte) []string {
 	var ret []string
 	i := 0
 	for i < len(data) {
-		i++
-		length := int(data[i])
-		i++
-		str := string(data[i : i+length])
+		// skip the field tag, followed by the string length as varint
+		length, n := binary.Uvarint(data[i+1:])
+		if n <= 0 || length > uint64(len(data)-i-1-n) {
+			break
+		}
+		i += 1 + n
+		str := string(data[i : i+int(length)])
 		ret = append(ret, str)
-		i += length
+		i += int(length)
 	}
 	return ret
 }
@@ -0,0 +1,70 @@
+package properties
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeserializeProtobufToStringSlice(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want []string
+	}{
+		{
+			// node.extensions entry as encoded by envoy: name (1), category (2), type_urls (6)
+			name: "wasm extension",
+			data: []byte("\x0a\x17envoy.filters.http.wasm" +
+				"\x12\x12envoy.filters.http" +
+				"\x32\x3etype.googleapis.com/envoy.extensions.filters.http.wasm.v3.Wasm"),
+			want: []string{
+				"envoy.filters.http.wasm",
+				"envoy.filters.http",
+				"type.googleapis.com/envoy.extensions.filters.http.wasm.v3.Wasm",
+			},
+		},
+		{
+			// a type url of 125 bytes, close to the single byte length limit
+			name: "long type url",
+			data: []byte("\x0a\x3eenvoy.load_balancing_policies.client_side_weighted_round_robin" +
+				"\x12\x1denvoy.load_balancing_policies" +
+				"\x32\x7dtype.googleapis.com/envoy.extensions.load_balancing_policies.client_side_weighted_round_robin.v3.ClientSideWeightedRoundRobin"),
+			want: []string{
+				"envoy.load_balancing_policies.client_side_weighted_round_robin",
+				"envoy.load_balancing_policies",
+				"type.googleapis.com/envoy.extensions.load_balancing_policies.client_side_weighted_round_robin.v3.ClientSideWeightedRoundRobin",
+			},
+		},
+		{
+			// lengths of 128 bytes and more take two varint bytes
+			name: "varint length",
+			data: []byte("\x0a\x82\x01" + strings.Repeat("a", 130) + "\x0a\x01b"),
+			want: []string{strings.Repeat("a", 130), "b"},
+		},
+		{
+			name: "truncated",
+			data: []byte("\x0a\x01a\x0a\x05bc"),
+			want: []string{"a"},
+		},
+		{
+			name: "missing length",
+			data: []byte("\x0a"),
+			want: []string{},
+		},
+		{
+			name: "empty",
+			data: []byte{},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deserializeProtobufToStringSlice(tt.data)
+			if len(got) != len(tt.want) || (len(got) > 0 && !reflect.DeepEqual(got, tt.want)) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
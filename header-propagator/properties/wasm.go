@@ -2,16 +2,12 @@
 // https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#wasm-attributes
 package properties
 
-import (
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
-
 // Get plugin name
 // This matches <metadata.name>.<metadata.namespace> in the istio WasmPlugin CR
 func GetPluginName() string {
 	pluginName, err := getPropertyString([]string{"plugin_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute plugin_name: %v", err)
+		logPropertyWarnf("failed reading wasm attribute plugin_name: %v", err)
 		return ""
 	}
 	return pluginName
@@ -22,7 +18,7 @@ func GetPluginName() string {
 func GetPluginRootId() string {
 	pluginRootId, err := getPropertyString([]string{"plugin_root_id"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute plugin_root_id: %v", err)
+		logPropertyWarnf("failed reading wasm attribute plugin_root_id: %v", err)
 		return ""
 	}
 	return pluginRootId
@@ -34,7 +30,7 @@ func GetPluginRootId() string {
 func GetPluginVmId() string {
 	pluginVmId, err := getPropertyString([]string{"plugin_vm_id"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute plugin_vm_id: %v", err)
+		logPropertyWarnf("failed reading wasm attribute plugin_vm_id: %v", err)
 		return ""
 	}
 	return pluginVmId
@@ -46,7 +42,7 @@ func GetPluginVmId() string {
 func GetClusterName() string {
 	clusterName, err := getPropertyString([]string{"cluster_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute cluster_name: %v", err)
+		logPropertyWarnf("failed reading wasm attribute cluster_name: %v", err)
 		return ""
 	}
 	return clusterName
@@ -57,7 +53,7 @@ func GetClusterName() string {
 func GetRouteName() string {
 	routeName, err := getPropertyString([]string{"route_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute route_name: %v", err)
+		logPropertyWarnf("failed reading wasm attribute route_name: %v", err)
 		return ""
 	}
 	return routeName
@@ -96,7 +92,7 @@ func (t TrafficDirection) String() string {
 func GetListenerDirection() TrafficDirection {
 	listenerDirection, err := getPropertyUint64([]string{"listener_direction"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute listener_direction: %v", err)
+		logPropertyWarnf("failed reading wasm attribute listener_direction: %v", err)
 		return 0
 	}
 
@@ -111,7 +107,7 @@ func GetListenerDirection() TrafficDirection {
 func GetNodeId() string {
 	nodeId, err := getPropertyString([]string{"node", "id"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute node.id: %v", err)
+		logPropertyWarnf("failed reading wasm attribute node.id: %v", err)
 	}
 	return nodeId
 }
@@ -126,7 +122,7 @@ func GetNodeId() string {
 func GetNodeCluster() string {
 	nodeCluster, err := getPropertyString([]string{"node", "cluster"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading wasm attribute node.cluster: %v", err)
+		logPropertyWarnf("failed reading wasm attribute node.cluster: %v", err)
 	}
 	return nodeCluster
 }
@@ -140,7 +136,7 @@ func GetNodeCluster() string {
 func GetNodeDynamicParams() string {
 	nodeDynamicParams, err := getPropertyString([]string{"node", "dynamic_parameters", "params"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.dynamic_parameters.params: %v", err)
+		logPropertyWarnf("failed reading node.dynamic_parameters.params: %v", err)
 	}
 	return nodeDynamicParams
 }
@@ -160,21 +156,21 @@ func GetNodeLocality() Locality {
 
 	region, err := getPropertyString([]string{"node", "locality", "region"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.locality.region: %v", err)
+		logPropertyWarnf("failed reading node.locality.region: %v", err)
 		result.Region = ""
 	}
 	result.Region = region
 
 	zone, err := getPropertyString([]string{"node", "locality", "zone"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.locality.zone: %v", err)
+		logPropertyWarnf("failed reading node.locality.zone: %v", err)
 		result.Zone = ""
 	}
 	result.Zone = zone
 
 	subzone, err := getPropertyString([]string{"node", "locality", "subzone"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.locality.subzone: %v", err)
+		logPropertyWarnf("failed reading node.locality.subzone: %v", err)
 		result.Subzone = ""
 	}
 	result.Subzone = subzone
@@ -188,7 +184,7 @@ func GetNodeLocality() Locality {
 func GetNodeUserAgentName() string {
 	nodeUserAgentName, err := getPropertyString([]string{"node", "user_agent_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.user_agent_name: %v", err)
+		logPropertyWarnf("failed reading node.user_agent_name: %v", err)
 	}
 	return nodeUserAgentName
 }
@@ -200,7 +196,7 @@ func GetNodeUserAgentName() string {
 func GetNodeUserAgentVersion() string {
 	nodeUserAgentVersion, err := getPropertyString([]string{"node", "user_agent_version"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.user_agent_version: %v", err)
+		logPropertyWarnf("failed reading node.user_agent_version: %v", err)
 	}
 	return nodeUserAgentVersion
 }
@@ -209,7 +205,7 @@ func GetNodeUserAgentVersion() string {
 func GetNodeUserAgentBuildVersion() map[string]string {
 	nodeUserAgentBuildVersion, err := getPropertyStringMap([]string{"node", "user_agent_build_version", "metadata"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.user_agent_build_version: %v", err)
+		logPropertyWarnf("failed reading node.user_agent_build_version: %v", err)
 	}
 	return nodeUserAgentBuildVersion
 }
@@ -228,7 +224,7 @@ func GetNodeExtensions() []Extension {
 	result := make([]Extension, 0)
 	extensionsRawSlice, err := getPropertyByteSliceSlice([]string{"node", "extensions"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.extensions: %v", err)
+		logPropertyWarnf("failed reading node.extensions: %v", err)
 	}
 
 	for _, extensionRawSlice := range extensionsRawSlice {
@@ -249,9 +245,9 @@ func GetNodeExtensions() []Extension {
 // repository for a given major version of an API. Client features use reverse DNS naming
 // scheme, for example "com.acme.feature"
 func GetNodeClientFeatures() []string {
-	nodeClientFeatures, err := proxywasm.GetProperty([]string{"node", "client_features"})
+	nodeClientFeatures, err := getProperty([]string{"node", "client_features"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.client_features: %v", err)
+		logPropertyWarnf("failed reading node.client_features: %v", err)
 	}
 	return deserializeProtobufToStringSlice(nodeClientFeatures)
 }
@@ -264,7 +260,7 @@ func GetNodeClientFeatures() []string {
 func GetNodeListeningAddresses() []string {
 	nodeListeningAddresses, err := getPropertyStringSlice([]string{"node", "listening_addresses"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading node.listening_addresses: %v", err)
+		logPropertyWarnf("failed reading node.listening_addresses: %v", err)
 	}
 	return nodeListeningAddresses
 }
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/tidwall/gjson"
+)
+
+// defaultBodyCaptureMaxBytes is the default number of body bytes printed.
+const defaultBodyCaptureMaxBytes = 4096
+
+// defaultBodyCaptureMaxBufferBytes is the default number of gzip encoded body bytes buffered,
+// well below the default envoy buffer limit of 1MiB.
+const defaultBodyCaptureMaxBufferBytes = 65536
+
+// defaultBodyCaptureContentTypes are the content types captured when none are configured,
+// limited to textual payloads that are readable in a log line.
+var defaultBodyCaptureContentTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/x-www-form-urlencoded",
+	"text/",
+}
+
+// bodyCapture prints request and/or response bodies of the printed requests. Bodies are
+// buffered in envoy until the end of the stream or until enough bytes arrived to be printed,
+// so the buffering never holds more than the printed part of a large body.
+type bodyCapture struct {
+	request        bool
+	response       bool
+	maxBytes       int      // number of (decompressed) body bytes printed
+	maxBufferBytes int      // number of gzip encoded body bytes buffered for decompression
+	contentTypes   []string // allowed media type prefixes, an empty list allows all content types
+}
+
+// bodyState tracks the capture of the request or response body of a single http stream.
+type bodyState struct {
+	enabled         bool // whether the body is buffered for capture
+	done            bool // whether the body was already captured
+	size            int  // number of body bytes received so far
+	contentType     string
+	contentEncoding string
+}
+
+// parseBodyCapture parses the "bodyCapture" section of the plugin configuration.
+//
+// Example configuration:
+//
+//	"bodyCapture": {
+//		"request": true,
+//		"response": true,
+//		"maxBytes": 1024,
+//		"maxBufferBytes": 65536,
+//		"contentTypes": ["application/json", "text/"]
+//	}
+func parseBodyCapture(jsonData gjson.Result) (bodyCapture, error) {
+	capture := bodyCapture{
+		request:        jsonData.Get("request").Bool(),
+		response:       jsonData.Get("response").Bool(),
+		maxBytes:       defaultBodyCaptureMaxBytes,
+		maxBufferBytes: defaultBodyCaptureMaxBufferBytes,
+		contentTypes:   defaultBodyCaptureContentTypes,
+	}
+
+	if maxBytes := jsonData.Get("maxBytes"); maxBytes.Exists() {
+		capture.maxBytes = int(maxBytes.Int())
+		if capture.maxBytes <= 0 {
+			return capture, fmt.Errorf("body capture max bytes %v is not positive", maxBytes.Raw)
+		}
+	}
+
+	if maxBufferBytes := jsonData.Get("maxBufferBytes"); maxBufferBytes.Exists() {
+		capture.maxBufferBytes = int(maxBufferBytes.Int())
+		if capture.maxBufferBytes <= 0 {
+			return capture, fmt.Errorf("body capture max buffer bytes %v is not positive", maxBufferBytes.Raw)
+		}
+	}
+
+	if contentTypes := jsonData.Get("contentTypes"); contentTypes.Exists() {
+		capture.contentTypes = make([]string, 0)
+		for _, contentType := range contentTypes.Array() {
+			capture.contentTypes = append(capture.contentTypes, strings.ToLower(contentType.String()))
+		}
+	}
+
+	return capture, nil
+}
+
+// allowed returns true if the body of the given content type is captured.
+func (b bodyCapture) allowed(contentType string) bool {
+	if len(b.contentTypes) == 0 {
+		return true
+	}
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, allowed := range b.contentTypes {
+		if strings.HasPrefix(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBodyState decides based on the headers whether a body gets captured.
+func (b bodyCapture) newBodyState(enabled bool, headers properties.Headers) bodyState {
+	contentType := headers.Get("content-type")
+	return bodyState{
+		enabled:         enabled && b.allowed(contentType),
+		contentType:     contentType,
+		contentEncoding: strings.ToLower(headers.Get("content-encoding")),
+	}
+}
+
+// buffering returns true while the body is buffered for capture.
+func (s *bodyState) buffering() bool {
+	return s.enabled && !s.done
+}
+
+// bufferLimit returns the number of buffered body bytes after which the body is captured
+// without waiting for the end of the stream. Only gzip encoded bodies need more bytes than
+// printed, as they are decompressed from the start.
+func (b bodyCapture) bufferLimit(state *bodyState) int {
+	if state.contentEncoding == "gzip" {
+		return b.maxBufferBytes
+	}
+	return b.maxBytes
+}
+
+// buffer records the size of the buffered body and returns true while it needs to be buffered
+// further before it can be captured.
+func (b bodyCapture) buffer(state *bodyState, bodySize int, endOfStream bool) bool {
+	state.size = bodySize
+	return !endOfStream && bodySize < b.bufferLimit(state)
+}
+
+// captureBody reads the buffered body, up to its buffer limit, and emits it as a dump of its
+// own. Bodies captured before the end of the stream are reported as truncated.
+func (ctx *httpContext) captureBody(name string, phase properties.Phase, state *bodyState, complete bool,
+	getBody func(start, maxSize int) ([]byte, error)) {
+	state.done = true
+
+	// read one byte past the limit, to tell whether more of the body was buffered
+	limit := ctx.pluginConfig.bodyCapture.bufferLimit(state)
+	raw, err := getBody(0, limit+1)
+	if err != nil && !errors.Is(err, types.ErrorStatusNotFound) {
+		proxywasm.LogErrorf("failed to read body in %v: %v", phase, err)
+		return
+	}
+
+	dump := newPropertyDump(ctx.contextID, phase)
+	g := dump.addGroup(name)
+	g.add("ContentType", state.contentType)
+	g.add("ContentEncoding", state.contentEncoding)
+	g.add("Size", state.size)
+
+	buffered := len(raw) <= limit
+	if !buffered {
+		raw = raw[:limit]
+	}
+	body, truncated, err := decodeBody(raw, state.contentEncoding, ctx.pluginConfig.bodyCapture.maxBytes, complete && buffered)
+	if err != nil {
+		g.add("Error", err.Error())
+	}
+	g.add("Truncated", truncated || !complete || !buffered)
+	g.add("Body", string(body))
+	ctx.pluginConfig.redaction.apply(g)
+
+	ctx.counters.capturedBodies++
+	ctx.emit(dump)
+}
+
+// decodeBody decompresses gzip encoded bodies and limits the result to maxBytes. Incomplete
+// gzip bodies, captured before the end of the stream, are decompressed as far as possible.
+func decodeBody(raw []byte, contentEncoding string, maxBytes int, complete bool) ([]byte, bool, error) {
+	if len(raw) == 0 {
+		return raw, false, nil
+	}
+	if contentEncoding != "gzip" {
+		if len(raw) > maxBytes {
+			return raw[:maxBytes], true, nil
+		}
+		return raw, false, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(raw))
+	if errors.Is(err, io.ErrUnexpectedEOF) && !complete {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decompress gzip body: %v", err)
+	}
+	defer reader.Close()
+
+	body, err := io.ReadAll(io.LimitReader(reader, int64(maxBytes)+1))
+	truncated := len(body) > maxBytes
+	if truncated {
+		body = body[:maxBytes]
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) && !complete {
+		return body, true, nil
+	}
+	if err != nil {
+		return body, truncated, fmt.Errorf("failed to decompress gzip body: %v", err)
+	}
+	return body, truncated, nil
+}
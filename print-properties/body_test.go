@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/tidwall/gjson"
+)
+
+// gzipBytes compresses the given data, failing the test on errors.
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// containsLog returns true if the given log line was emitted.
+func containsLog(logs []string, line string) bool {
+	for _, log := range logs {
+		if log == line {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		raw             []byte
+		contentEncoding string
+		want            string
+		truncated       bool
+	}{
+		{"identity", []byte("hello"), "", "hello", false},
+		{"identity truncated", []byte("hello world"), "", "hello", true},
+		{"gzip", gzipBytes(t, "hello"), "gzip", "hello", false},
+		{"gzip truncated", gzipBytes(t, "hello world"), "gzip", "hello", true},
+		{"empty gzip", nil, "gzip", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, truncated, err := decodeBody(tt.raw, tt.contentEncoding, 5, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want || truncated != tt.truncated {
+				t.Errorf("expected %q (truncated %v), got %q (truncated %v)", tt.want, tt.truncated, body, truncated)
+			}
+		})
+	}
+
+	if _, _, err := decodeBody([]byte("not gzip"), "gzip", 5, true); err == nil {
+		t.Error("expected an error for an invalid gzip body")
+	}
+
+	// a gzip body cut off at the buffer limit is decompressed as far as possible
+	partial := gzipBytes(t, "hello world")
+	for _, raw := range [][]byte{partial[:len(partial)-4], partial[:4]} {
+		if _, truncated, err := decodeBody(raw, "gzip", 5, false); err != nil || !truncated {
+			t.Errorf("expected an incomplete gzip body to be truncated without error, got %v (%v)", truncated, err)
+		}
+	}
+	if _, _, err := decodeBody(partial[:4], "gzip", 5, true); err == nil {
+		t.Error("expected an error for a complete but cut off gzip body")
+	}
+}
+
+func TestBodyCaptureAllowed(t *testing.T) {
+	capture, err := parseBodyCapture(gjson.Parse(`{"request": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for contentType, want := range map[string]bool{
+		"application/json":          true,
+		"Application/JSON; charset": true,
+		"text/plain":                true,
+		"application/grpc":          false,
+		"image/png":                 false,
+		"":                          false,
+	} {
+		if got := capture.allowed(contentType); got != want {
+			t.Errorf("expected %v for %q, got %v", want, contentType, got)
+		}
+	}
+
+	capture, _ = parseBodyCapture(gjson.Parse(`{"contentTypes": []}`))
+	if !capture.allowed("image/png") {
+		t.Error("expected an empty allowlist to allow all content types")
+	}
+
+	if _, err := parseBodyCapture(gjson.Parse(`{"maxBytes": 0}`)); err == nil {
+		t.Error("expected an error for a non positive max bytes")
+	}
+	if _, err := parseBodyCapture(gjson.Parse(`{"maxBufferBytes": -1}`)); err == nil {
+		t.Error("expected an error for a non positive max buffer bytes")
+	}
+}
+
+func TestRequestBodyCapture(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"bodyCapture": {"request": true, "maxBytes": 11}}`)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/post"}, {"content-type", "text/plain"}}, false)
+	if action := host.CallOnRequestBody(contextID, []byte("hello "), false); action != types.ActionPause {
+		t.Fatalf("expected the body to be buffered, got %v", action)
+	}
+	if action := host.CallOnRequestBody(contextID, []byte("world, bye"), true); action != types.ActionContinue {
+		t.Fatalf("expected the body to continue at end of stream, got %v", action)
+	}
+
+	// the emulator passes the frame size instead of the buffered size, so Size is not checked
+	logs := host.GetInfoLogs()
+	for _, line := range []string{">> Truncated: true", ">> Body: hello world"} {
+		if !containsLog(logs, line) {
+			t.Errorf("expected log line %q, got %v", line, logs)
+		}
+	}
+}
+
+func TestResponseBodyCaptureWithTrailers(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"bodyCapture": {"response": true}}`)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get"}}, true)
+	host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}, {"content-type", "application/json"}, {"content-encoding", "gzip"}}, false)
+	if action := host.CallOnResponseBody(contextID, gzipBytes(t, `{"ok":true}`), false); action != types.ActionPause {
+		t.Fatalf("expected the body to be buffered, got %v", action)
+	}
+	host.CallOnResponseTrailers(contextID, [][2]string{{"grpc-status", "0"}})
+
+	if logs := host.GetInfoLogs(); !containsLog(logs, `>> Body: {"ok":true}`) {
+		t.Errorf("expected the decompressed body to be captured on trailers, got %v", logs)
+	}
+}
+
+func TestBodyCaptureSkipsUnmatchedRequests(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"match": {"sampleRate": 0}, "bodyCapture": {"request": true}}`)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/post"}, {"content-type", "text/plain"}}, false)
+	if action := host.CallOnRequestBody(contextID, []byte("hello"), false); action != types.ActionContinue {
+		t.Fatalf("expected the body of an unmatched request not to be buffered, got %v", action)
+	}
+}
+
+// Bodies are captured as soon as enough bytes arrived, the rest of the stream is not buffered
+func TestRequestBodyCaptureBeforeEndOfStream(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"bodyCapture": {"request": true, "maxBytes": 5}}`)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/post"}, {"content-type", "text/plain"}}, false)
+	if action := host.CallOnRequestBody(contextID, []byte("hello world"), false); action != types.ActionContinue {
+		t.Fatalf("expected the body to continue once max bytes arrived, got %v", action)
+	}
+	if action := host.CallOnRequestBody(contextID, []byte(", bye"), true); action != types.ActionContinue {
+		t.Fatalf("expected the body to continue at end of stream, got %v", action)
+	}
+
+	logs := host.GetInfoLogs()
+	for _, line := range []string{">> Size: 11", ">> Truncated: true", ">> Body: hello"} {
+		if !containsLog(logs, line) {
+			t.Errorf("expected log line %q, got %v", line, logs)
+		}
+	}
+	captured := 0
+	for _, log := range logs {
+		if strings.HasPrefix(log, ">> Body: ") {
+			captured++
+		}
+	}
+	if captured != 1 {
+		t.Errorf("expected the body to be captured once, got %d times", captured)
+	}
+}
+
+func TestGzipBodyCaptureBufferLimit(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"bodyCapture": {"response": true, "maxBufferBytes": 32}}`)
+	defer reset()
+
+	var body strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&body, "%d,", i*i*7919)
+	}
+	encoded := gzipBytes(t, body.String())
+	if len(encoded) <= 32 {
+		t.Fatalf("expected the gzip body to exceed the buffer limit, got %d bytes", len(encoded))
+	}
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get"}}, true)
+	host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}, {"content-type", "text/plain"}, {"content-encoding", "gzip"}}, false)
+	if action := host.CallOnResponseBody(contextID, encoded, false); action != types.ActionContinue {
+		t.Fatalf("expected the body to continue once max buffer bytes arrived, got %v", action)
+	}
+
+	logs := host.GetInfoLogs()
+	if !containsLog(logs, ">> Truncated: true") {
+		t.Errorf("expected the body to be truncated, got %v", logs)
+	}
+	for _, log := range logs {
+		if strings.HasPrefix(log, ">> Error: ") {
+			t.Errorf("expected the partial gzip body to be decompressed without error, got %q", log)
+		}
+		if printed := strings.TrimPrefix(log, ">> Body: "); printed != log && !strings.HasPrefix(body.String(), printed) {
+			t.Errorf("expected a prefix of the decompressed body, got %q", printed)
+		}
+	}
+}
+
+func TestBodyCaptureRedaction(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"bodyCapture": {"request": true}, "redaction": {"properties": ["Body"]}}`)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/post"}, {"content-type", "text/plain"}}, false)
+	host.CallOnRequestBody(contextID, []byte("s3cr3t"), true)
+
+	logs := host.GetInfoLogs()
+	for _, line := range []string{">> Size: 6", ">> Body: [REDACTED]"} {
+		if !containsLog(logs, line) {
+			t.Errorf("expected log line %q, got %v", line, logs)
+		}
+	}
+	for _, log := range logs {
+		if strings.Contains(log, "s3cr3t") {
+			t.Errorf("expected the body to be redacted, got %q", log)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+)
+
+// capturePaths lists the attribute paths read by the properties package getters. Paths with
+// a plugin chosen key (e.g. dynamic metadata namespaces) are left out, except the istio peer
+// metadata filter state.
+var capturePaths = [][]string{
+	{"cluster_name"},
+	{"connection", "dns_san_local_certificate"},
+	{"connection", "dns_san_peer_certificate"},
+	{"connection", "id"},
+	{"connection", "mtls"},
+	{"connection", "requested_server_name"},
+	{"connection", "sha256_peer_certificate_digest"},
+	{"connection", "subject_local_certificate"},
+	{"connection", "subject_peer_certificate"},
+	{"connection", "termination_details"},
+	{"connection", "tls_version"},
+	{"connection", "transport_failure_reason"},
+	{"connection", "uri_san_local_certificate"},
+	{"connection", "uri_san_peer_certificate"},
+	{"destination", "address"},
+	{"destination", "port"},
+	{"filter_state", "downstream_peer", "app"},
+	{"filter_state", "downstream_peer", "cluster"},
+	{"filter_state", "downstream_peer", "labels"},
+	{"filter_state", "downstream_peer", "name"},
+	{"filter_state", "downstream_peer", "namespace"},
+	{"filter_state", "downstream_peer", "revision"},
+	{"filter_state", "downstream_peer", "service"},
+	{"filter_state", "downstream_peer", "type"},
+	{"filter_state", "downstream_peer", "version"},
+	{"filter_state", "downstream_peer", "workload"},
+	{"filter_state", "upstream_peer", "app"},
+	{"filter_state", "upstream_peer", "cluster"},
+	{"filter_state", "upstream_peer", "labels"},
+	{"filter_state", "upstream_peer", "name"},
+	{"filter_state", "upstream_peer", "namespace"},
+	{"filter_state", "upstream_peer", "revision"},
+	{"filter_state", "upstream_peer", "service"},
+	{"filter_state", "upstream_peer", "type"},
+	{"filter_state", "upstream_peer", "version"},
+	{"filter_state", "upstream_peer", "workload"},
+	{"listener_direction"},
+	{"node", "client_features"},
+	{"node", "cluster"},
+	{"node", "cluster_metadata", "filter_metadata", "istio"},
+	{"node", "dynamic_parameters", "params"},
+	{"node", "extensions"},
+	{"node", "id"},
+	{"node", "listener_metadata", "filter_metadata", "istio"},
+	{"node", "listening_addresses"},
+	{"node", "locality", "region"},
+	{"node", "locality", "subzone"},
+	{"node", "locality", "zone"},
+	{"node", "metadata", "ANNOTATIONS"},
+	{"node", "metadata", "APP_CONTAINERS"},
+	{"node", "metadata", "CLUSTER_ID"},
+	{"node", "metadata", "ENVOY_PROMETHEUS_PORT"},
+	{"node", "metadata", "ENVOY_STATUS_PORT"},
+	{"node", "metadata", "INSTANCE_IPS"},
+	{"node", "metadata", "INTERCEPTION_MODE"},
+	{"node", "metadata", "ISTIO_PROXY_SHA"},
+	{"node", "metadata", "ISTIO_VERSION"},
+	{"node", "metadata", "LABELS"},
+	{"node", "metadata", "MESH_ID"},
+	{"node", "metadata", "NAME"},
+	{"node", "metadata", "NAMESPACE"},
+	{"node", "metadata", "NODE_NAME"},
+	{"node", "metadata", "OWNER"},
+	{"node", "metadata", "PILOT_SAN"},
+	{"node", "metadata", "POD_PORTS"},
+	{"node", "metadata", "PROXY_CONFIG"},
+	{"node", "metadata", "PROXY_CONFIG", "binaryPath"},
+	{"node", "metadata", "PROXY_CONFIG", "concurrency"},
+	{"node", "metadata", "PROXY_CONFIG", "configPath"},
+	{"node", "metadata", "PROXY_CONFIG", "controlPlaneAuthPolicy"},
+	{"node", "metadata", "PROXY_CONFIG", "discoveryAddress"},
+	{"node", "metadata", "PROXY_CONFIG", "drainDuration"},
+	{"node", "metadata", "PROXY_CONFIG", "extraStatTags"},
+	{"node", "metadata", "PROXY_CONFIG", "holdApplicationUntilProxyStarts"},
+	{"node", "metadata", "PROXY_CONFIG", "proxyAdminPort"},
+	{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionPrefixes"},
+	{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionRegexps"},
+	{"node", "metadata", "PROXY_CONFIG", "proxyStatsMatcher", "inclusionSuffixes"},
+	{"node", "metadata", "PROXY_CONFIG", "serviceCluster"},
+	{"node", "metadata", "PROXY_CONFIG", "statNameLength"},
+	{"node", "metadata", "PROXY_CONFIG", "statusPort"},
+	{"node", "metadata", "PROXY_CONFIG", "terminationDrainDuration"},
+	{"node", "metadata", "PROXY_CONFIG", "tracing", "datadog", "address"},
+	{"node", "metadata", "PROXY_CONFIG", "tracing", "openCensusAgent", "address"},
+	{"node", "metadata", "PROXY_CONFIG", "tracing", "zipkin", "address"},
+	{"node", "metadata", "SERVICE_ACCOUNT"},
+	{"node", "metadata", "WORKLOAD_NAME"},
+	{"node", "route_metadata", "filter_metadata", "istio"},
+	{"node", "upstream_host_metadata", "filter_metadata", "istio"},
+	{"node", "user_agent_build_version", "metadata"},
+	{"node", "user_agent_name"},
+	{"node", "user_agent_version"},
+	{"plugin_name"},
+	{"plugin_root_id"},
+	{"plugin_vm_id"},
+	{"request", "duration"},
+	{"request", "headers"},
+	{"request", "host"},
+	{"request", "id"},
+	{"request", "method"},
+	{"request", "path"},
+	{"request", "protocol"},
+	{"request", "query"},
+	{"request", "referer"},
+	{"request", "scheme"},
+	{"request", "size"},
+	{"request", "time"},
+	{"request", "total_size"},
+	{"request", "url_path"},
+	{"request", "useragent"},
+	{"response", "backend_latency"},
+	{"response", "code"},
+	{"response", "code_details"},
+	{"response", "flags"},
+	{"response", "grpc_status"},
+	{"response", "headers"},
+	{"response", "size"},
+	{"response", "total_size"},
+	{"response", "trailers"},
+	{"route_name"},
+	{"source", "address"},
+	{"source", "port"},
+	{"upstream", "address"},
+	{"upstream", "cx_pool_ready_duration"},
+	{"upstream", "dns_san_local_certificate"},
+	{"upstream", "dns_san_peer_certificate"},
+	{"upstream", "local_address"},
+	{"upstream", "port"},
+	{"upstream", "request_attempt_count"},
+	{"upstream", "sha256_peer_certificate_digest"},
+	{"upstream", "subject_local_certificate"},
+	{"upstream", "subject_peer_certificate"},
+	{"upstream", "tls_version"},
+	{"upstream", "transport_failure_reason"},
+	{"upstream", "uri_san_local_certificate"},
+	{"upstream", "uri_san_peer_certificate"},
+	{"xds", "cluster_metadata", "filter_metadata", "istio"},
+	{"xds", "cluster_name"},
+	{"xds", "filter_chain_name"},
+	{"xds", "listener_direction"},
+	{"xds", "listener_metadata", "filter_metadata", "istio"},
+	{"xds", "route_metadata", "filter_metadata", "istio"},
+	{"xds", "route_name"},
+	{"xds", "upstream_host_metadata", "filter_metadata", "istio"},
+	{"xds", "virtual_host_metadata", "filter_metadata", "istio"},
+	{"xds", "virtual_host_name"},
+}
+
+// captureFixture logs the raw host encoded values of all attributes available in the phase,
+// as a fixture that properties.LoadFixture replays verbatim. Captured from different proxy
+// versions, these fixtures detect changes in attribute encodings (see properties/testdata).
+//
+// Example output:
+//
+//	fixture captured in OnHttpRequestHeaders: {"raw":[{"path":["listener_direction"],"value":"AQAAAAAAAAA="},...]}
+func captureFixture(phase properties.Phase) {
+	raw := make([]map[string]interface{}, 0)
+	for _, path := range capturePaths {
+		if !properties.IsAvailable(phase, strings.Join(path, ".")) {
+			continue
+		}
+		value, err := proxywasm.GetProperty(path)
+		if err != nil || len(value) == 0 {
+			continue
+		}
+		raw = append(raw, map[string]interface{}{
+			"path":  path,
+			"value": base64.StdEncoding.EncodeToString(value),
+		})
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"raw": raw})
+	if err != nil {
+		proxywasm.LogErrorf("failed to marshal fixture of %v: %v", phase, err)
+		return
+	}
+	proxywasm.LogInfof("fixture captured in %v: %s", phase, data)
+}
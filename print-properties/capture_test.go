@@ -0,0 +1,68 @@
+//go:build proxytest
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// capturedFixture returns the fixture logged for the given phase.
+func capturedFixture(t *testing.T, logs []string, phase properties.Phase) string {
+	t.Helper()
+	prefix := "fixture captured in " + phase.String() + ": "
+	for _, log := range logs {
+		if strings.HasPrefix(log, prefix) {
+			return strings.TrimPrefix(log, prefix)
+		}
+	}
+	t.Fatalf("expected a fixture to be captured in %v, got %v", phase, logs)
+	return ""
+}
+
+// captureRequestFixture captures a fixture in the request headers phase of a plugin running
+// with the given properties.
+func captureRequestFixture(t *testing.T, props map[string][]byte) string {
+	t.Helper()
+	host, reset := startTestPlugin(t, `{"captureFixture": true, "onHttpRequestHeaders": {"printRequestProperties": true}}`)
+	defer reset()
+
+	for path, value := range props {
+		if err := host.SetProperty(strings.Split(path, "."), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get"}}, false)
+	return capturedFixture(t, host.GetInfoLogs(), properties.PhaseHttpRequestHeaders)
+}
+
+func TestCaptureFixture(t *testing.T) {
+	headers := properties.SerializeStringMap(map[string]string{":path": "/get", "x-request-id": "req-1"})
+	fixture := captureRequestFixture(t, map[string][]byte{
+		"request.headers":    headers,
+		"listener_direction": properties.SerializeUint64(uint64(properties.Inbound)),
+		"response.code":      properties.SerializeUint64(200),
+	})
+	if strings.Contains(fixture, `"response"`) {
+		t.Errorf("expected attributes not available in the phase to be left out, got %v", fixture)
+	}
+
+	// Replay the fixture in a new host, which must return the exact captured bytes
+	host, reset := proxytest.NewHostEmulator(proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{}))
+	defer reset()
+	if err := properties.LoadFixture(host, []byte(fixture)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := host.GetProperty([]string{"request", "headers"}); err != nil || string(value) != string(headers) {
+		t.Errorf("expected the captured request headers to be replayed, got %q (%v)", value, err)
+	}
+	if got := properties.GetListenerDirection(); got != properties.Inbound {
+		t.Errorf("expected the captured listener direction to be replayed, got %v", got)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "time"
+
+// pluginCounters are plugin level statistics, printed on tick with "onTick.printCounters".
+type pluginCounters struct {
+	started         time.Time // time the plugin configuration was (re)loaded
+	ticks           uint64
+	httpContexts    uint64 // http streams handled by the plugin
+	tcpContexts     uint64 // tcp connections handled by the plugin
+	printedRequests uint64 // http streams that were logged or returned in a debug response
+	debugResponses  uint64
+	capturedBodies  uint64
+}
+
+// collect adds the counters to a dump group.
+func (c *pluginCounters) collect(g *groupDump) {
+	g.add("PluginStartTime", c.started)
+	g.add("Ticks", c.ticks)
+	g.add("HttpContexts", c.httpContexts)
+	g.add("TcpContexts", c.tcpContexts)
+	g.add("PrintedRequests", c.printedRequests)
+	g.add("DebugResponses", c.debugResponses)
+	g.add("CapturedBodies", c.capturedBodies)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"print-properties/properties"
+)
+
+func TestOnTick(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"onTick": {"interval": 5000, "printWasmProperties": true, "printCounters": true},
+		"match": {"headers": {"x-debug": "1"}}
+	}`)
+	defer reset()
+
+	if period := host.GetTickPeriod(); period != 5000 {
+		t.Fatalf("expected a tick period of 5000ms, got %v", period)
+	}
+	if err := host.SetProperty([]string{"plugin_name"}, []byte("print-properties")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, debug := range []string{"0", "1", "1"} {
+		contextID := host.InitializeHttpContext()
+		host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/"}, {"x-debug", debug}}, true)
+	}
+	host.Tick()
+
+	logs := host.GetInfoLogs()
+	for _, line := range []string{
+		"********** OnTick **********",
+		">> GetPluginName: print-properties",
+		">> Ticks: 1",
+		">> HttpContexts: 3",
+		">> PrintedRequests: 2",
+	} {
+		if !containsLog(logs, line) {
+			t.Errorf("expected log line %q, got %v", line, logs)
+		}
+	}
+}
+
+func TestOnTickDisabled(t *testing.T) {
+	host, reset := startTestPlugin(t, `{"onPluginStart": {"printWasmProperties": true}}`)
+	defer reset()
+
+	if period := host.GetTickPeriod(); period != 0 {
+		t.Fatalf("expected no tick period, got %v", period)
+	}
+}
+
+func TestTickPhaseAvailability(t *testing.T) {
+	for attribute, want := range map[string]bool{
+		"node":        true,
+		"plugin_name": true,
+		"request":     false,
+		"upstream":    false,
+	} {
+		if got := properties.IsAvailable(properties.PhaseTick, attribute); got != want {
+			t.Errorf("expected availability %v for %v on tick, got %v", want, attribute, got)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+	"github.com/tidwall/gjson"
+)
+
+// debugMode defines how the property dump is returned to the client of a debug request.
+type debugMode string
+
+const (
+	// debugModeResponseHeader adds the dump to the upstream response as a response header.
+	debugModeResponseHeader debugMode = "responseHeader"
+	// debugModeLocalReply short-circuits the request with a local reply carrying the dump.
+	debugModeLocalReply debugMode = "localReply"
+)
+
+// defaultDebugResponseHeader is the response header carrying the dump in responseHeader mode.
+const defaultDebugResponseHeader = "x-print-properties"
+
+// defaultDebugMaxHeaderSize is the maximum size in bytes of the dump in responseHeader mode,
+// well below the 60 KiB envoy allows for all response headers by default.
+const defaultDebugMaxHeaderSize = 8192
+
+// debugTruncatedHeaderSuffix is appended to the response header name to report the size of a
+// truncated dump.
+const debugTruncatedHeaderSuffix = "-truncated"
+
+// debugResponse returns the properties collected for a request to its client, when the
+// request carries the configured debug header with the configured secret. The debug header is
+// removed before the request is forwarded, so the secret never reaches the upstream.
+type debugResponse struct {
+	enabled        bool
+	header         string
+	secret         string
+	mode           debugMode
+	responseHeader string
+	maxHeaderSize  int
+}
+
+// parseDebugResponse parses the "debugResponse" section of the plugin configuration.
+//
+// Example configuration:
+//
+//	"debugResponse": {
+//		"header": "x-debug-secret",
+//		"secret": "s3cr3t",
+//		"mode": "responseHeader",
+//		"maxHeaderSize": 4096
+//	}
+func parseDebugResponse(jsonData gjson.Result) (debugResponse, error) {
+	debug := debugResponse{
+		mode:           debugModeResponseHeader,
+		responseHeader: defaultDebugResponseHeader,
+		maxHeaderSize:  defaultDebugMaxHeaderSize,
+	}
+	if !jsonData.Exists() {
+		return debug, nil
+	}
+
+	debug.enabled = true
+	debug.header = strings.ToLower(jsonData.Get("header").String())
+	debug.secret = jsonData.Get("secret").String()
+	if debug.header == "" || debug.secret == "" {
+		return debug, fmt.Errorf("debug response requires a header and a secret")
+	}
+
+	if mode := jsonData.Get("mode"); mode.Exists() {
+		switch debugMode(mode.String()) {
+		case debugModeResponseHeader, debugModeLocalReply:
+			debug.mode = debugMode(mode.String())
+		default:
+			return debug, fmt.Errorf("unknown debug response mode %q", mode.String())
+		}
+	}
+
+	if responseHeader := jsonData.Get("responseHeader").String(); responseHeader != "" {
+		debug.responseHeader = strings.ToLower(responseHeader)
+	}
+
+	if maxHeaderSize := jsonData.Get("maxHeaderSize"); maxHeaderSize.Exists() {
+		if maxHeaderSize.Int() <= 0 {
+			return debug, fmt.Errorf("debug response maxHeaderSize must be positive, got %v", maxHeaderSize.Raw)
+		}
+		debug.maxHeaderSize = int(maxHeaderSize.Int())
+	}
+
+	return debug, nil
+}
+
+// requested returns true if the request carries the debug header with the configured secret.
+func (d debugResponse) requested(headers properties.Headers) bool {
+	if !d.enabled || !headers.Has(d.header) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(headers.Get(d.header)), []byte(d.secret)) == 1
+}
+
+// marshalDumps serializes the dumps collected for a request as a single line JSON array.
+func marshalDumps(dumps []*propertyDump) ([]byte, error) {
+	documents := make([]map[string]interface{}, 0, len(dumps))
+	for _, dump := range dumps {
+		documents = append(documents, dump.toJson())
+	}
+	return json.Marshal(documents)
+}
+
+// sendDebugLocalReply short-circuits the request with the collected dumps as JSON body.
+func (ctx *httpContext) sendDebugLocalReply() {
+	body, err := marshalDumps(ctx.debugDumps)
+	if err != nil {
+		proxywasm.LogErrorf("failed to marshal debug response: %v", err)
+		body = []byte("[]")
+	}
+	headers := [][2]string{{"content-type", "application/json"}}
+	if err := proxywasm.SendHttpResponse(200, headers, body, -1); err != nil {
+		proxywasm.LogErrorf("failed to send debug response: %v", err)
+	}
+}
+
+// truncateDump cuts a serialized dump to at most max bytes without splitting a UTF-8 character.
+func truncateDump(dump []byte, max int) []byte {
+	if len(dump) <= max {
+		return dump
+	}
+	end := max
+	for end > 0 && !utf8.RuneStart(dump[end]) {
+		end--
+	}
+	return dump[:end]
+}
+
+// addDebugResponseHeader adds the collected dumps as JSON to the response headers. A dump
+// larger than the configured maximum is truncated, its full size is reported in an extra
+// response header and the complete dump is logged instead.
+func (ctx *httpContext) addDebugResponseHeader() {
+	debug := ctx.pluginConfig.debugResponse
+	value, err := marshalDumps(ctx.debugDumps)
+	if err != nil {
+		proxywasm.LogErrorf("failed to marshal debug response: %v", err)
+		return
+	}
+
+	if len(value) > debug.maxHeaderSize {
+		proxywasm.LogWarnf("debug response of %v bytes truncated to %v bytes: %s", len(value), debug.maxHeaderSize, value)
+		if err := proxywasm.AddHttpResponseHeader(debug.responseHeader+debugTruncatedHeaderSuffix, fmt.Sprint(len(value))); err != nil {
+			proxywasm.LogErrorf("failed to add debug response header: %v", err)
+		}
+		value = truncateDump(value, debug.maxHeaderSize)
+	}
+	if err := proxywasm.AddHttpResponseHeader(debug.responseHeader, string(value)); err != nil {
+		proxywasm.LogErrorf("failed to add debug response header: %v", err)
+	}
+}
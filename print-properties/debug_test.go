@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+	"github.com/tidwall/gjson"
+)
+
+const debugTestConfig = `{
+	"match": {"sampleRate": 0},
+	"onHttpRequestHeaders": {"printRequestProperties": true},
+	"onHttpResponseHeaders": {"printResponseProperties": true},
+	"debugResponse": {"header": "x-debug-secret", "secret": "s3cr3t", "mode": "%v"}
+}`
+
+// debugTestHeaders returns request headers carrying the given debug secret.
+func debugTestHeaders(secret string) [][2]string {
+	return [][2]string{{":path", "/get"}, {":authority", "httpbin.org"}, {"x-debug-secret", secret}}
+}
+
+// headerValue returns the value of the given header, or an empty string if not present.
+func headerValue(headers [][2]string, name string) string {
+	for _, header := range headers {
+		if header[0] == name {
+			return header[1]
+		}
+	}
+	return ""
+}
+
+func TestParseDebugResponse(t *testing.T) {
+	debug, err := parseDebugResponse(gjson.Parse(`{"header": "X-Debug-Secret", "secret": "s3cr3t"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !debug.enabled || debug.header != "x-debug-secret" || debug.mode != debugModeResponseHeader || debug.responseHeader != defaultDebugResponseHeader || debug.maxHeaderSize != defaultDebugMaxHeaderSize {
+		t.Errorf("unexpected defaults %+v", debug)
+	}
+
+	if debug, _ := parseDebugResponse(gjson.Result{}); debug.enabled {
+		t.Error("expected debug response to be disabled without configuration")
+	}
+
+	for _, config := range []string{
+		`{"header": "x-debug-secret"}`,
+		`{"secret": "s3cr3t"}`,
+		`{"header": "x-debug-secret", "secret": "s3cr3t", "mode": "body"}`,
+		`{"header": "x-debug-secret", "secret": "s3cr3t", "maxHeaderSize": 0}`,
+		`{"header": "x-debug-secret", "secret": "s3cr3t", "maxHeaderSize": -1}`,
+	} {
+		if _, err := parseDebugResponse(gjson.Parse(config)); err == nil {
+			t.Errorf("expected an error for %v", config)
+		}
+	}
+}
+
+func TestDebugLocalReply(t *testing.T) {
+	host, reset := startTestPlugin(t, fmt.Sprintf(debugTestConfig, debugModeLocalReply))
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	if action := host.CallOnRequestHeaders(contextID, debugTestHeaders("s3cr3t"), true); action != types.ActionPause {
+		t.Fatalf("expected the request to be paused, got %v", action)
+	}
+
+	reply := host.GetSentLocalResponse(contextID)
+	if reply == nil {
+		t.Fatal("expected a local reply")
+	}
+	if reply.StatusCode != 200 || headerValue(reply.Headers, "content-type") != "application/json" {
+		t.Errorf("unexpected local reply %v %v", reply.StatusCode, reply.Headers)
+	}
+
+	var dumps []map[string]interface{}
+	if err := json.Unmarshal(reply.Data, &dumps); err != nil {
+		t.Fatalf("expected a JSON body, got %s: %v", reply.Data, err)
+	}
+	if len(dumps) != 1 || dumps[0]["phase"] != "OnHttpRequestHeaders" {
+		t.Errorf("expected the request headers dump, got %s", reply.Data)
+	}
+
+	// Sampled out by the match filters, so nothing gets logged
+	if logs := host.GetInfoLogs(); len(logs) != 0 {
+		t.Errorf("expected no log output, got %v", logs)
+	}
+}
+
+func TestDebugResponseHeader(t *testing.T) {
+	host, reset := startTestPlugin(t, fmt.Sprintf(debugTestConfig, debugModeResponseHeader))
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	if action := host.CallOnRequestHeaders(contextID, debugTestHeaders("s3cr3t"), false); action != types.ActionContinue {
+		t.Fatalf("expected the request to continue, got %v", action)
+	}
+	if value := headerValue(host.GetCurrentRequestHeaders(contextID), "x-debug-secret"); value != "" {
+		t.Errorf("expected the debug header to be removed, got %v", value)
+	}
+
+	host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}}, false)
+	value := headerValue(host.GetCurrentResponseHeaders(contextID), defaultDebugResponseHeader)
+
+	var dumps []map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &dumps); err != nil {
+		t.Fatalf("expected a JSON response header, got %v: %v", value, err)
+	}
+	if len(dumps) != 2 || dumps[0]["phase"] != "OnHttpRequestHeaders" || dumps[1]["phase"] != "OnHttpResponseHeaders" {
+		t.Errorf("expected the request and response headers dumps, got %v", value)
+	}
+}
+
+func TestTruncateDump(t *testing.T) {
+	tests := []struct {
+		dump string
+		max  int
+		want string
+	}{
+		{`[{"a":"b"}]`, 100, `[{"a":"b"}]`},
+		{`[{"a":"b"}]`, 11, `[{"a":"b"}]`},
+		{`[{"a":"b"}]`, 5, `[{"a"`},
+		{`["ééé"]`, 4, `["é`},
+		{`["ééé"]`, 5, `["é`},
+	}
+
+	for _, tt := range tests {
+		if got := string(truncateDump([]byte(tt.dump), tt.max)); got != tt.want {
+			t.Errorf("truncateDump(%q, %v): got %q, want %q", tt.dump, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestDebugResponseHeaderTruncated(t *testing.T) {
+	config := `{
+		"match": {"sampleRate": 0},
+		"onHttpRequestHeaders": {"printRequestProperties": true},
+		"debugResponse": {"header": "x-debug-secret", "secret": "s3cr3t", "maxHeaderSize": 64}
+	}`
+	host, reset := startTestPlugin(t, config)
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, debugTestHeaders("s3cr3t"), false)
+	host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}}, false)
+
+	headers := host.GetCurrentResponseHeaders(contextID)
+	value := headerValue(headers, defaultDebugResponseHeader)
+	if len(value) == 0 || len(value) > 64 {
+		t.Errorf("expected the dump to be truncated to 64 bytes, got %v bytes", len(value))
+	}
+	size := headerValue(headers, defaultDebugResponseHeader+debugTruncatedHeaderSuffix)
+	if size == "" {
+		t.Fatal("expected the size of the truncated dump in a response header")
+	}
+
+	prefix := "debug response of " + size + " bytes truncated to 64 bytes: "
+	full := ""
+	for _, log := range host.GetWarnLogs() {
+		if strings.HasPrefix(log, prefix) {
+			full = strings.TrimPrefix(log, prefix)
+		}
+	}
+	if strconv.Itoa(len(full)) != size || !strings.HasPrefix(full, value) || !gjson.Valid(full) {
+		t.Errorf("expected the complete dump of %v bytes in the log, got %v", size, full)
+	}
+}
+
+func TestDebugWrongSecret(t *testing.T) {
+	host, reset := startTestPlugin(t, fmt.Sprintf(debugTestConfig, debugModeLocalReply))
+	defer reset()
+
+	contextID := host.InitializeHttpContext()
+	if action := host.CallOnRequestHeaders(contextID, debugTestHeaders("guess"), true); action != types.ActionContinue {
+		t.Fatalf("expected the request to continue, got %v", action)
+	}
+	if reply := host.GetSentLocalResponse(contextID); reply != nil {
+		t.Errorf("expected no local reply, got %v", reply)
+	}
+	if value := headerValue(host.GetCurrentRequestHeaders(contextID), "x-debug-secret"); value != "guess" {
+		t.Errorf("expected the debug header to be forwarded untouched, got %v", value)
+	}
+}
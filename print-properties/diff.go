@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"print-properties/properties"
+)
+
+// diffChange is the value of a property that changed since the previous phase.
+type diffChange struct {
+	from interface{}
+	to   interface{}
+}
+
+func (c diffChange) String() string {
+	return fmt.Sprintf("%+v -> %+v", c.from, c.to)
+}
+
+// Diff operations, used as prefix of the property names in a diff.
+const (
+	diffAdded   = "+"
+	diffRemoved = "-"
+	diffChanged = "~"
+)
+
+// phaseSnapshot holds the flattened property values of the groups printed in a phase, indexed
+// by group name and then by property name (with map keys as "GetRequestHeaders[x-foo]").
+type phaseSnapshot map[string]map[string]interface{}
+
+// flattenValue splits maps and header lists into one entry per key, so a diff shows the
+// individual headers or metadata keys that changed instead of the whole map.
+func flattenValue(name string, value interface{}, entries map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]string:
+		for key, val := range v {
+			entries[name+"["+key+"]"] = val
+		}
+	case properties.Headers:
+		for _, key := range v.Names() {
+			entries[name+"["+key+"]"] = strings.Join(v.Values(key), ",")
+		}
+	case properties.QueryParams:
+		for key, values := range v {
+			entries[name+"["+key+"]"] = strings.Join(values, ",")
+		}
+	default:
+		entries[name] = value
+	}
+}
+
+// diff replaces the properties of every group in the dump by the properties that were added,
+// removed or changed since the given snapshot, and returns the snapshot of this dump. Groups
+// seen for the first time are reported as added entirely.
+func (d *propertyDump) diff(previous phaseSnapshot) phaseSnapshot {
+	current := make(phaseSnapshot)
+	for _, g := range d.groups {
+		if g.skipped {
+			continue
+		}
+
+		entries := make(map[string]interface{})
+		for _, p := range g.properties {
+			flattenValue(p.name, p.value, entries)
+		}
+		current[g.name] = entries
+
+		names := make([]string, 0, len(entries))
+		for name := range entries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		before := previous[g.name]
+		g.properties = nil
+		for _, name := range names {
+			value := entries[name]
+			old, ok := before[name]
+			switch {
+			case !ok:
+				g.add(diffAdded+name, value)
+			case fmt.Sprintf("%+v", old) != fmt.Sprintf("%+v", value):
+				g.add(diffChanged+name, diffChange{from: old, to: value})
+			}
+		}
+
+		removed := make([]string, 0)
+		for name := range before {
+			if _, ok := entries[name]; !ok {
+				removed = append(removed, name)
+			}
+		}
+		sort.Strings(removed)
+		for _, name := range removed {
+			g.add(diffRemoved+name, before[name])
+		}
+	}
+
+	// Keep the groups not printed in this phase, so they diff against their last printed state
+	for name, entries := range previous {
+		if _, ok := current[name]; !ok {
+			current[name] = entries
+		}
+	}
+	return current
+}
@@ -0,0 +1,131 @@
+//go:build proxytest
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"print-properties/properties"
+)
+
+// newTestDump creates a dump with a single request group holding the given properties.
+func newTestDump(phase properties.Phase, props ...property) *propertyDump {
+	dump := newPropertyDump(1, phase)
+	g := dump.addGroup("printRequestProperties")
+	for _, p := range props {
+		g.add(p.name, p.value)
+	}
+	return dump
+}
+
+// diffLines renders the properties of the first group of a dump as "name: value" lines.
+func diffLines(dump *propertyDump) []string {
+	lines := make([]string, 0)
+	for _, p := range dump.groups[0].properties {
+		lines = append(lines, fmt.Sprintf("%v: %+v", p.name, p.value))
+	}
+	return lines
+}
+
+func TestDiff(t *testing.T) {
+	first := newTestDump(properties.PhaseHttpRequestHeaders,
+		property{"GetRequestPath", "/get"},
+		property{"GetRequestHeaders", map[string]string{"x-removed": "1", "x-changed": "a"}},
+	)
+	snapshot := first.diff(nil)
+	want := []string{"+GetRequestHeaders[x-changed]: a", "+GetRequestHeaders[x-removed]: 1", "+GetRequestPath: /get"}
+	if got := diffLines(first); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected everything to be added on the first phase, got %v", got)
+	}
+
+	second := newTestDump(properties.PhaseHttpStreamDone,
+		property{"GetRequestPath", "/get"},
+		property{"GetRequestHeaders", map[string]string{"x-changed": "b", "x-added": "2"}},
+	)
+	second.diff(snapshot)
+	want = []string{"+GetRequestHeaders[x-added]: 2", "~GetRequestHeaders[x-changed]: a -> b", "-GetRequestHeaders[x-removed]: 1"}
+	if got := diffLines(second); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestDiffKeepsGroupsNotPrinted(t *testing.T) {
+	snapshot := newTestDump(properties.PhaseHttpRequestHeaders, property{"GetRequestPath", "/get"}).diff(nil)
+
+	other := newPropertyDump(1, properties.PhaseHttpResponseHeaders)
+	other.addGroup("printResponseProperties").add("GetResponseCode", 200)
+	snapshot = other.diff(snapshot)
+
+	last := newTestDump(properties.PhaseHttpStreamDone, property{"GetRequestPath", "/get"})
+	last.diff(snapshot)
+	if got := diffLines(last); len(got) != 0 {
+		t.Errorf("expected no changes for the request group, got %v", got)
+	}
+}
+
+func TestDiffJson(t *testing.T) {
+	value := jsonValue(diffChange{from: 0, to: 200})
+	if fmt.Sprint(value) != "map[from:0 to:200]" {
+		t.Errorf("expected a from/to object, got %v", value)
+	}
+}
+
+// phaseLogs returns the property lines logged for the given phase.
+func phaseLogs(logs []string, phase properties.Phase) []string {
+	lines := make([]string, 0)
+	inPhase := false
+	for _, log := range logs {
+		if strings.HasPrefix(log, "**********") {
+			inPhase = log == fmt.Sprintf("********** %v **********", phase)
+			continue
+		}
+		if inPhase {
+			lines = append(lines, log)
+		}
+	}
+	return lines
+}
+
+func TestDiffMode(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"diff": true,
+		"onHttpRequestHeaders": {"printRequestProperties": true},
+		"onHttpResponseHeaders": {"printRequestProperties": true}
+	}`)
+	defer reset()
+
+	setRequestHeaders := func(headers map[string]string) {
+		t.Helper()
+		if err := host.SetProperty([]string{"request", "headers"}, properties.SerializeStringMap(headers)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := host.SetProperty([]string{"request", "path"}, []byte("/get")); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.SetProperty([]string{"request", "time"}, properties.SerializeTimestamp(time.Unix(1697620363, 0))); err != nil {
+		t.Fatal(err)
+	}
+
+	setRequestHeaders(map[string]string{":path": "/get", "x-removed": "1", "x-changed": "a"})
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get"}}, true)
+	setRequestHeaders(map[string]string{":path": "/get", "x-changed": "b", "x-added": "2"})
+	host.CallOnResponseHeaders(contextID, [][2]string{{":status", "200"}}, true)
+
+	logs := host.GetInfoLogs()
+	if got := phaseLogs(logs, properties.PhaseHttpRequestHeaders); !containsLog(got, ">> +GetRequestPath: /get") || !containsLog(got, ">> +GetRequestHeaders[x-removed]: 1") {
+		t.Errorf("expected all properties to be added in the first phase, got %v", got)
+	}
+	want := []string{
+		">> +GetRequestHeaders[x-added]: 2",
+		">> ~GetRequestHeaders[x-changed]: a -> b",
+		">> -GetRequestHeaders[x-removed]: 1",
+	}
+	if got := phaseLogs(logs, properties.PhaseHttpResponseHeaders); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected only the changes in the second phase %v, got %v", want, got)
+	}
+}
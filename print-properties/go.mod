@@ -4,10 +4,13 @@ go 1.19
 
 require github.com/tetratelabs/proxy-wasm-go-sdk v0.22.0
 
-require print-properties/properties v0.0.0
+require (
+	github.com/tidwall/gjson v1.17.0
+	print-properties/properties v0.0.0
+)
 
 require (
-	github.com/tidwall/gjson v1.17.0 // indirect
+	github.com/tetratelabs/wazero v1.0.0-rc.1 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
 )
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"print-properties/properties"
 
 	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
@@ -19,34 +21,71 @@ type vmContext struct {
 
 // NewPluginContext is used for creating PluginContext for each plugin configuration.
 func (*vmContext) NewPluginContext(contextID uint32) types.PluginContext {
-	return &pluginContext{}
+	return &pluginContext{contextID: contextID, counters: &pluginCounters{}}
 }
 
 type pluginContext struct {
 	types.DefaultPluginContext
+	contextID    uint32
 	pluginConfig pluginConfig
+	counters     *pluginCounters
 }
 
 type pluginConfig struct {
-	OnPluginStart          propertiesPrinting `json:"onPluginStart"`
-	OnHttpRequestHeaders   propertiesPrinting `json:"onHttpRequestHeaders"`
-	OnHttpRequestBody      propertiesPrinting `json:"onHttpRequestBody"`
-	OnHttpRequestTrailers  propertiesPrinting `json:"onHttpRequestTrailers"`
-	OnHttpResponseHeaders  propertiesPrinting `json:"onHttpResponseHeaders"`
-	OnHttpResponseBody     propertiesPrinting `json:"onHttpResponseBody"`
-	OnHttpResponseTrailers propertiesPrinting `json:"onHttpResponseTrailers"`
-	OnHttpStreamDone       propertiesPrinting `json:"onHttpStreamDone"`
-}
-
-type propertiesPrinting struct {
-	PrintWasmProperties            bool `json:"printWasmProperties"`
-	PrintNodeMetadataProperties    bool `json:"printNodeMetadataProperties"`
-	PrintNodeProxyConfigProperties bool `json:"printNodeProxyConfigProperties"`
-	PrintXdsProperties             bool `json:"printXdsProperties"`
-	PrintUpstreamProperties        bool `json:"printUpstreamProperties"`
-	PrintConnectionProperties      bool `json:"printConnectionProperties"`
-	PrintResponseProperties        bool `json:"printResponseProperties"`
-	PrintRequestProperties         bool `json:"printRequestProperties"`
+	outputFormat  outputFormat
+	matcher       requestMatcher                       // decides which http requests get printed
+	debugResponse debugResponse                        // returns the properties to debug requests
+	redaction     redaction                            // removes sensitive values before printing
+	bodyCapture   bodyCapture                          // prints request and response bodies
+	diff          bool                                 // prints only the changes since the previous phase
+	tickInterval  uint32                               // period of the onTick event in milliseconds, 0 disables it
+	printCounters bool                                 // prints the plugin counters on tick
+	networkFilter bool                                 // creates tcp instead of http contexts
+	capture       bool                                 // logs the raw attribute values as test fixture
+	groups        map[properties.Phase][]propertyGroup // property groups to print for each phase
+}
+
+// pluginEvent maps a plugin configuration section onto the phase it configures.
+type pluginEvent struct {
+	name  string
+	phase properties.Phase
+}
+
+// pluginEvents lists the configurable plugin, http and tcp events.
+var pluginEvents = []pluginEvent{
+	{"onPluginStart", properties.PhasePluginStart},
+	{"onHttpRequestHeaders", properties.PhaseHttpRequestHeaders},
+	{"onHttpRequestBody", properties.PhaseHttpRequestBody},
+	{"onHttpRequestTrailers", properties.PhaseHttpRequestTrailers},
+	{"onHttpResponseHeaders", properties.PhaseHttpResponseHeaders},
+	{"onHttpResponseBody", properties.PhaseHttpResponseBody},
+	{"onHttpResponseTrailers", properties.PhaseHttpResponseTrailers},
+	{"onHttpStreamDone", properties.PhaseHttpStreamDone},
+	{"onTick", properties.PhaseTick},
+	{"onNewConnection", properties.PhaseTcpNewConnection},
+	{"onDownstreamData", properties.PhaseTcpDownstreamData},
+	{"onUpstreamData", properties.PhaseTcpUpstreamData},
+	{"onDownstreamClose", properties.PhaseTcpDownstreamClose},
+}
+
+// propertyGroup is a set of properties printed together, enabled per event with
+// "<event>.<name>: true" in the plugin configuration.
+type propertyGroup struct {
+	name      string
+	attribute string // attribute used to check whether the group is available in a phase
+	collect   func(g *groupDump)
+}
+
+// propertyGroups is the registry of all printable property groups.
+var propertyGroups = []propertyGroup{
+	{"printWasmProperties", "plugin_name", collectWasmProperties},
+	{"printNodeMetadataProperties", "node", collectNodeMetadataProperties},
+	{"printNodeProxyConfigProperties", "node", collectNodeProxyConfigProperties},
+	{"printXdsProperties", "xds", collectXdsProperties},
+	{"printUpstreamProperties", "upstream", collectUpstreamProperties},
+	{"printConnectionProperties", "connection", collectConnectionProperties},
+	{"printResponseProperties", "response", collectResponseProperties},
+	{"printRequestProperties", "request", collectRequestProperties},
 }
 
 // OnPluginStart is called for all plugin contexts (after OnVmStart if this is the VM context).
@@ -67,20 +106,54 @@ func (p *pluginContext) OnPluginStart(pluginConfigurationSize int) types.OnPlugi
 		return types.OnPluginStartStatusFailed
 	}
 
-	p.pluginConfig = parseConfigData(configData)
-	if anyPrintBoolTrue(p.pluginConfig.OnPluginStart) {
-		proxywasm.LogInfo("********** OnPluginStart **********")
-		printProperties(p.pluginConfig.OnPluginStart)
+	pluginConfig, err := parseConfigData(configData)
+	if err != nil {
+		proxywasm.LogErrorf("Invalid plugin configuration: %v", err)
+		return types.OnPluginStartStatusFailed
 	}
+	p.pluginConfig = pluginConfig
+	p.counters.started = time.Now()
+
+	if p.pluginConfig.tickInterval > 0 {
+		if err := proxywasm.SetTickPeriodMilliSeconds(p.pluginConfig.tickInterval); err != nil {
+			proxywasm.LogErrorf("Failed to set tick period: %v", err)
+			return types.OnPluginStartStatusFailed
+		}
+	}
+
+	printProperties(p.pluginConfig, p.contextID, properties.PhasePluginStart)
 	return types.OnPluginStartStatusOK
 }
 
+// OnTick is called periodically based on the tick period set, it prints the property groups
+// configured for onTick together with the plugin counters.
+func (p *pluginContext) OnTick() {
+	properties.SetPhase(properties.PhaseTick)
+	p.counters.ticks++
+
+	dump := collectProperties(p.pluginConfig, p.contextID, properties.PhaseTick)
+	if p.pluginConfig.printCounters {
+		if dump == nil {
+			dump = newPropertyDump(p.contextID, properties.PhaseTick)
+		}
+		p.counters.collect(dump.addGroup("printCounters"))
+	}
+	if dump != nil {
+		dump.log(p.pluginConfig.outputFormat)
+	}
+}
+
 // NewHttpContext is used for creating HttpContext for each Http stream.
 // Return nil to indicate this PluginContext is not for HttpContext
 func (p *pluginContext) NewHttpContext(contextID uint32) types.HttpContext {
+	if p.pluginConfig.networkFilter {
+		return nil
+	}
+	p.counters.httpContexts++
 	return &httpContext{
 		contextID:    contextID,
 		pluginConfig: p.pluginConfig,
+		counters:     p.counters,
 	}
 }
 
@@ -88,6 +161,13 @@ type httpContext struct {
 	types.DefaultHttpContext
 	contextID    uint32
 	pluginConfig pluginConfig
+	counters     *pluginCounters
+	matched      bool            // whether the request passed the match filters, decided on request headers
+	debug        bool            // whether the request asked for a debug response
+	debugDumps   []*propertyDump // properties collected for the debug response
+	requestBody  bodyState
+	responseBody bodyState
+	previous     phaseSnapshot // properties printed in the previous phases, used in diff mode
 }
 
 // *********************************************
@@ -97,9 +177,26 @@ type httpContext struct {
 // OnHttpRequestHeaders is called when request headers arrive.
 // Return types.ActionPause if you want to stop sending headers to the upstream.
 func (ctx *httpContext) OnHttpRequestHeaders(numHeaders int, endOfStream bool) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpRequestHeaders) {
-		proxywasm.LogInfo("********** OnHttpRequestHeaders **********")
-		printProperties(ctx.pluginConfig.OnHttpRequestHeaders)
+	properties.SetPhase(properties.PhaseHttpRequestHeaders)
+	headers := properties.GetRequestHeaderList()
+	ctx.matched = ctx.pluginConfig.matcher.matches(headers)
+	ctx.debug = ctx.pluginConfig.debugResponse.requested(headers)
+	if ctx.printed() {
+		ctx.counters.printedRequests++
+	}
+	if ctx.debug {
+		ctx.counters.debugResponses++
+		if err := proxywasm.RemoveHttpRequestHeader(ctx.pluginConfig.debugResponse.header); err != nil {
+			proxywasm.LogErrorf("failed to remove debug header: %v", err)
+		}
+	}
+
+	ctx.printProperties(properties.PhaseHttpRequestHeaders)
+	ctx.requestBody = ctx.pluginConfig.bodyCapture.newBodyState(ctx.pluginConfig.bodyCapture.request && ctx.printed(), headers)
+
+	if ctx.debug && ctx.pluginConfig.debugResponse.mode == debugModeLocalReply {
+		ctx.sendDebugLocalReply()
+		return types.ActionPause
 	}
 	return types.ActionContinue
 }
@@ -109,9 +206,13 @@ func (ctx *httpContext) OnHttpRequestHeaders(numHeaders int, endOfStream bool) t
 // Return types.ActionPause if you want to buffer the body and stop sending body to the upstream.
 // Even after returning types.ActionPause, this will be called when an unseen frame arrives.
 func (ctx *httpContext) OnHttpRequestBody(bodySize int, endOfStream bool) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpRequestBody) {
-		proxywasm.LogInfo("********** OnHttpRequestBody **********")
-		printProperties(ctx.pluginConfig.OnHttpRequestBody)
+	if ctx.requestBody.buffering() && ctx.pluginConfig.bodyCapture.buffer(&ctx.requestBody, bodySize, endOfStream) {
+		properties.SetPhase(properties.PhaseHttpRequestBody)
+		return types.ActionPause
+	}
+	ctx.printProperties(properties.PhaseHttpRequestBody)
+	if ctx.requestBody.buffering() {
+		ctx.captureBody("requestBody", properties.PhaseHttpRequestBody, &ctx.requestBody, endOfStream, proxywasm.GetHttpRequestBody)
 	}
 	return types.ActionContinue
 }
@@ -119,9 +220,10 @@ func (ctx *httpContext) OnHttpRequestBody(bodySize int, endOfStream bool) types.
 // OnHttpRequestTrailers is called when request trailers arrive.
 // Return types.ActionPause if you want to stop sending trailers to the upstream.
 func (ctx *httpContext) OnHttpRequestTrailers(bodySize int) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpRequestTrailers) {
-		proxywasm.LogInfo("********** OnHttpRequestTrailers **********")
-		printProperties(ctx.pluginConfig.OnHttpRequestTrailers)
+	ctx.printProperties(properties.PhaseHttpRequestTrailers)
+	// Bodies followed by trailers never see end of stream in the body callback
+	if ctx.requestBody.buffering() {
+		ctx.captureBody("requestBody", properties.PhaseHttpRequestTrailers, &ctx.requestBody, true, proxywasm.GetHttpRequestBody)
 	}
 	return types.ActionContinue
 }
@@ -133,9 +235,11 @@ func (ctx *httpContext) OnHttpRequestTrailers(bodySize int) types.Action {
 // OnHttpResponseHeaders is called when response headers arrive.
 // Return types.ActionPause if you want to stop sending headers to downstream.
 func (ctx *httpContext) OnHttpResponseHeaders(numHeaders int, endOfStream bool) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpResponseHeaders) {
-		proxywasm.LogInfo("********** OnHttpResponseHeaders **********")
-		printProperties(ctx.pluginConfig.OnHttpResponseHeaders)
+	ctx.printProperties(properties.PhaseHttpResponseHeaders)
+	ctx.responseBody = ctx.pluginConfig.bodyCapture.newBodyState(ctx.pluginConfig.bodyCapture.response && ctx.printed(), properties.GetResponseHeaderList())
+
+	if ctx.debug && ctx.pluginConfig.debugResponse.mode == debugModeResponseHeader {
+		ctx.addDebugResponseHeader()
 	}
 	return types.ActionContinue
 }
@@ -145,9 +249,13 @@ func (ctx *httpContext) OnHttpResponseHeaders(numHeaders int, endOfStream bool)
 // Return types.ActionPause if you want to buffer the body and stop sending body to the downtream.
 // Even after returning types.ActionPause, this will be called when an unseen frame arrives.
 func (ctx *httpContext) OnHttpResponseBody(bodySize int, endOfStream bool) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpResponseBody) {
-		proxywasm.LogInfo("********** OnHttpResponseBody **********")
-		printProperties(ctx.pluginConfig.OnHttpResponseBody)
+	if ctx.responseBody.buffering() && ctx.pluginConfig.bodyCapture.buffer(&ctx.responseBody, bodySize, endOfStream) {
+		properties.SetPhase(properties.PhaseHttpResponseBody)
+		return types.ActionPause
+	}
+	ctx.printProperties(properties.PhaseHttpResponseBody)
+	if ctx.responseBody.buffering() {
+		ctx.captureBody("responseBody", properties.PhaseHttpResponseBody, &ctx.responseBody, endOfStream, proxywasm.GetHttpResponseBody)
 	}
 	return types.ActionContinue
 }
@@ -155,9 +263,10 @@ func (ctx *httpContext) OnHttpResponseBody(bodySize int, endOfStream bool) types
 // OnHttpResponseTrailers is called when response trailers arrive.
 // Return types.ActionPause if you want to stop sending trailers to the downstream.
 func (ctx *httpContext) OnHttpResponseTrailers(bodySize int) types.Action {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpResponseTrailers) {
-		proxywasm.LogInfo("********** OnHttpResponseTrailers **********")
-		printProperties(ctx.pluginConfig.OnHttpResponseTrailers)
+	ctx.printProperties(properties.PhaseHttpResponseTrailers)
+	// Bodies followed by trailers never see end of stream in the body callback
+	if ctx.responseBody.buffering() {
+		ctx.captureBody("responseBody", properties.PhaseHttpResponseTrailers, &ctx.responseBody, true, proxywasm.GetHttpResponseBody)
 	}
 	return types.ActionContinue
 }
@@ -166,10 +275,53 @@ func (ctx *httpContext) OnHttpResponseTrailers(bodySize int) types.Action {
 // You can retrieve the HTTP request/response information (such as headers, etc.) during this call.
 // This can be used to implement logging features.
 func (ctx *httpContext) OnHttpStreamDone() {
-	if anyPrintBoolTrue(ctx.pluginConfig.OnHttpStreamDone) {
-		proxywasm.LogInfo("********** OnHttpStreamDone **********")
-		printProperties(ctx.pluginConfig.OnHttpStreamDone)
+	ctx.printProperties(properties.PhaseHttpStreamDone)
+}
+
+// *********************************************
+// TCP PATH
+// *********************************************
+
+// NewTcpContext is used for creating TcpContext for each Tcp stream. The sdk prefers http
+// contexts, so this is only called when the plugin is configured as network filter.
+func (p *pluginContext) NewTcpContext(contextID uint32) types.TcpContext {
+	if !p.pluginConfig.networkFilter {
+		return nil
 	}
+	p.counters.tcpContexts++
+	return &tcpContext{
+		contextID:    contextID,
+		pluginConfig: p.pluginConfig,
+	}
+}
+
+type tcpContext struct {
+	types.DefaultTcpContext
+	contextID    uint32
+	pluginConfig pluginConfig
+}
+
+// OnNewConnection is called when the Tcp connection is established between downstream and upstream.
+func (ctx *tcpContext) OnNewConnection() types.Action {
+	printProperties(ctx.pluginConfig, ctx.contextID, properties.PhaseTcpNewConnection)
+	return types.ActionContinue
+}
+
+// OnDownstreamData is called when a data frame arrives from the downstream connection.
+func (ctx *tcpContext) OnDownstreamData(dataSize int, endOfStream bool) types.Action {
+	printProperties(ctx.pluginConfig, ctx.contextID, properties.PhaseTcpDownstreamData)
+	return types.ActionContinue
+}
+
+// OnUpstreamData is called when a data frame arrives from the upstream connection.
+func (ctx *tcpContext) OnUpstreamData(dataSize int, endOfStream bool) types.Action {
+	printProperties(ctx.pluginConfig, ctx.contextID, properties.PhaseTcpUpstreamData)
+	return types.ActionContinue
+}
+
+// OnDownstreamClose is called when the downstream connection is closed.
+func (ctx *tcpContext) OnDownstreamClose(peerType types.PeerType) {
+	printProperties(ctx.pluginConfig, ctx.contextID, properties.PhaseTcpDownstreamClose)
 }
 
 // *********************************************
@@ -177,241 +329,279 @@ func (ctx *httpContext) OnHttpStreamDone() {
 // *********************************************
 
 // parseConfigData parses the configuration data into the pluginConfig.
-func parseConfigData(data []byte) pluginConfig {
-	type eventNames struct {
-		name   string
-		fields []string
-	}
-	events := []eventNames{
-		{"onPluginStart", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpRequestHeaders", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpRequestBody", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpRequestTrailers", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpResponseHeaders", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpResponseBody", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpResponseTrailers", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-		{"onHttpStreamDone", []string{"printWasmProperties", "printNodeMetadataProperties", "printNodeProxyConfigProperties", "printXdsProperties", "printUpstreamProperties", "printConnectionProperties", "printResponseProperties", "printRequestProperties"}},
-	}
+func parseConfigData(data []byte) (pluginConfig, error) {
 	jsonData := gjson.ParseBytes(data)
-	config := pluginConfig{}
-
-	for _, event := range events {
-		propPrint := propertiesPrinting{}
-		for _, field := range event.fields {
-			path := event.name + "." + field
-			value := jsonData.Get(path).Bool()
-			switch field {
-			case "printWasmProperties":
-				propPrint.PrintWasmProperties = value
-			case "printNodeMetadataProperties":
-				propPrint.PrintNodeMetadataProperties = value
-			case "printNodeProxyConfigProperties":
-				propPrint.PrintNodeProxyConfigProperties = value
-			case "printXdsProperties":
-				propPrint.PrintXdsProperties = value
-			case "printUpstreamProperties":
-				propPrint.PrintConnectionProperties = value
-			case "printConnectionProperties":
-				propPrint.PrintNodeProxyConfigProperties = value
-			case "printResponseProperties":
-				propPrint.PrintResponseProperties = value
-			case "printRequestProperties":
-				propPrint.PrintRequestProperties = value
+	config := pluginConfig{
+		outputFormat: outputFormatText,
+		groups:       make(map[properties.Phase][]propertyGroup),
+	}
+
+	if jsonData.Get("outputFormat").Exists() {
+		format, err := parseOutputFormat(jsonData.Get("outputFormat").String())
+		if err != nil {
+			return config, err
+		}
+		config.outputFormat = format
+	}
+
+	matcher, err := parseRequestMatcher(jsonData.Get("match"))
+	if err != nil {
+		return config, err
+	}
+	config.matcher = matcher
+
+	debugResponse, err := parseDebugResponse(jsonData.Get("debugResponse"))
+	if err != nil {
+		return config, err
+	}
+	config.debugResponse = debugResponse
+
+	redaction, err := parseRedaction(jsonData.Get("redaction"))
+	if err != nil {
+		return config, err
+	}
+	config.redaction = redaction
+
+	bodyCapture, err := parseBodyCapture(jsonData.Get("bodyCapture"))
+	if err != nil {
+		return config, err
+	}
+	config.bodyCapture = bodyCapture
+
+	config.diff = jsonData.Get("diff").Bool()
+	config.tickInterval = uint32(jsonData.Get("onTick.interval").Uint())
+	config.printCounters = jsonData.Get("onTick.printCounters").Bool()
+	config.networkFilter = jsonData.Get("networkFilter").Bool()
+	config.capture = jsonData.Get("captureFixture").Bool()
+
+	for _, event := range pluginEvents {
+		for _, group := range propertyGroups {
+			if jsonData.Get(event.name + "." + group.name).Bool() {
+				config.groups[event.phase] = append(config.groups[event.phase], group)
 			}
 		}
-		switch event.name {
-		case "onPluginStart":
-			config.OnPluginStart = propPrint
-		case "onHttpRequestHeaders":
-			config.OnHttpRequestHeaders = propPrint
-		case "onHttpRequestBody":
-			config.OnHttpRequestBody = propPrint
-		case "onHttpRequestTrailers":
-			config.OnHttpRequestTrailers = propPrint
-		case "onHttpResponseHeaders":
-			config.OnHttpResponseHeaders = propPrint
-		case "onHttpResponseBody":
-			config.OnHttpResponseBody = propPrint
-		case "onHttpResponseTrailers":
-			config.OnHttpResponseTrailers = propPrint
-		case "onHttpStreamDone":
-			config.OnHttpStreamDone = propPrint
+	}
+	return config, nil
+}
+
+// printProperties prints the properties of an http phase if the request passed the match
+// filters, and keeps them for the debug response if the request asked for one.
+func (ctx *httpContext) printProperties(phase properties.Phase) {
+	properties.SetPhase(phase)
+	if !ctx.printed() {
+		return
+	}
+	dump := collectProperties(ctx.pluginConfig, ctx.contextID, phase)
+	if dump == nil {
+		return
+	}
+	if ctx.pluginConfig.diff {
+		ctx.previous = dump.diff(ctx.previous)
+	}
+	ctx.emit(dump)
+	if ctx.pluginConfig.capture && ctx.matched {
+		captureFixture(phase)
+	}
+}
+
+// printed returns true if the request gets logged or returned in a debug response.
+func (ctx *httpContext) printed() bool {
+	return ctx.matched || ctx.debug
+}
+
+// emit logs a dump if the request passed the match filters, and keeps it for the debug
+// response if the request asked for one.
+func (ctx *httpContext) emit(dump *propertyDump) {
+	if ctx.matched {
+		dump.log(ctx.pluginConfig.outputFormat)
+	}
+	if ctx.debug {
+		ctx.debugDumps = append(ctx.debugDumps, dump)
+	}
+}
+
+// printProperties collects the property groups configured for the given phase and logs them
+// in the configured output format.
+func printProperties(config pluginConfig, contextID uint32, phase properties.Phase) {
+	properties.SetPhase(phase)
+	if dump := collectProperties(config, contextID, phase); dump != nil {
+		dump.log(config.outputFormat)
+		if config.capture {
+			captureFixture(phase)
+		}
+	}
+}
+
+// collectProperties collects the property groups configured for the given phase, skipping the
+// groups that are not available in that phase, and redacts sensitive values. Returns nil if no
+// group is configured.
+func collectProperties(config pluginConfig, contextID uint32, phase properties.Phase) *propertyDump {
+	groups := config.groups[phase]
+	if len(groups) == 0 {
+		return nil
+	}
+
+	dump := newPropertyDump(contextID, phase)
+	for _, group := range groups {
+		g := dump.addGroup(group.name)
+		if !properties.IsAvailable(phase, group.attribute) {
+			g.skipped = true
+			continue
 		}
+		group.collect(g)
+		config.redaction.apply(g)
 	}
-	return config
-}
-
-// anyPrintBoolTrue checks if any of the properties should be printed.
-func anyPrintBoolTrue(p propertiesPrinting) bool {
-	return p.PrintWasmProperties ||
-		p.PrintNodeMetadataProperties ||
-		p.PrintNodeProxyConfigProperties ||
-		p.PrintXdsProperties ||
-		p.PrintUpstreamProperties ||
-		p.PrintConnectionProperties ||
-		p.PrintResponseProperties ||
-		p.PrintRequestProperties
-}
-
-// printProperties logs the properties based on the configuration.
-func printProperties(p propertiesPrinting) {
-	if p.PrintWasmProperties {
-		printWasmProperties()
-	}
-	if p.PrintNodeMetadataProperties {
-		printNodeMetadataProperties()
-	}
-	if p.PrintNodeProxyConfigProperties {
-		printNodeProxyConfigProperties()
-	}
-	if p.PrintXdsProperties {
-		printXdsProperties()
-	}
-	if p.PrintUpstreamProperties {
-		printUpstreamProperties()
-	}
-	if p.PrintConnectionProperties {
-		printConnectionProperties()
-	}
-	if p.PrintResponseProperties {
-		printResponseProperties()
-	}
-	if p.PrintRequestProperties {
-		printRequestProperties()
-	}
-}
-
-func printWasmProperties() {
-	proxywasm.LogInfof(">> GetPluginName: %v", properties.GetPluginName())
-	proxywasm.LogInfof(">> GetPluginRootId: %v", properties.GetPluginRootId())
-	proxywasm.LogInfof(">> GetPluginVmId: %v", properties.GetPluginVmId())
-	proxywasm.LogInfof(">> GetClusterName: %v", properties.GetClusterName())
-	proxywasm.LogInfof(">> GetRouteName: %v", properties.GetRouteName())
-	proxywasm.LogInfof(">> GetListenerDirection: %v", properties.GetListenerDirection())
-	proxywasm.LogInfof(">> GetNodeId: %v", properties.GetNodeId())
-	proxywasm.LogInfof(">> GetNodeCluster: %v", properties.GetNodeCluster())
-	proxywasm.LogInfof(">> GetNodeDynamicParams: %v", properties.GetNodeDynamicParams())
-	proxywasm.LogInfof(">> GetNodeLocality: %+v", properties.GetNodeLocality())
-	proxywasm.LogInfof(">> GetNodeUserAgentName: %v", properties.GetNodeUserAgentName())
-	proxywasm.LogInfof(">> GetNodeUserAgentVersion: %v", properties.GetNodeUserAgentVersion())
-	proxywasm.LogInfof(">> GetNodeUserAgentBuildVersion: %v", properties.GetNodeUserAgentBuildVersion())
-	proxywasm.LogInfof(">> GetNodeExtensions: %+v", properties.GetNodeExtensions())
-	proxywasm.LogInfof(">> GetNodeClientFeatures: %v", properties.GetNodeClientFeatures())
-	proxywasm.LogInfof(">> GetNodeListeningAddresses: %v", properties.GetNodeListeningAddresses())
-	proxywasm.LogInfof(">> GetClusterMetadata: %+v", properties.GetClusterMetadata())
-	proxywasm.LogInfof(">> GetListenerMetadata: %+v", properties.GetListenerMetadata())
-	proxywasm.LogInfof(">> GetRouteMetadata: %+v", properties.GetRouteMetadata())
-	proxywasm.LogInfof(">> GetUpstreamHostMetadata: %+v", properties.GetUpstreamHostMetadata())
-}
-
-func printNodeMetadataProperties() {
-	proxywasm.LogInfof(">> GetNodeMetadataAnnotations: %v", properties.GetNodeMetadataAnnotations())
-	proxywasm.LogInfof(">> GetNodeMetadataAppContainers: %v", properties.GetNodeMetadataAppContainers())
-	proxywasm.LogInfof(">> GetNodeMetadataClusterId: %v", properties.GetNodeMetadataClusterId())
-	proxywasm.LogInfof(">> GetNodeMetadataEnvoyPrometheusPort: %v", properties.GetNodeMetadataEnvoyPrometheusPort())
-	proxywasm.LogInfof(">> GetNodeMetadataEnvoyStatusPort: %v", properties.GetNodeMetadataEnvoyStatusPort())
-	proxywasm.LogInfof(">> GetNodeMetadataInstanceIps: %v", properties.GetNodeMetadataInstanceIps())
-	proxywasm.LogInfof(">> GetNodeMetadataInterceptionMode: %v", properties.GetNodeMetadataInterceptionMode())
-	proxywasm.LogInfof(">> GetNodeMetadataIstioProxySha: %v", properties.GetNodeMetadataIstioProxySha())
-	proxywasm.LogInfof(">> GetNodeMetadataIstioVersion: %v", properties.GetNodeMetadataIstioVersion())
-	proxywasm.LogInfof(">> GetNodeMetadataLabels: %v", properties.GetNodeMetadataLabels())
-	proxywasm.LogInfof(">> GetNodeMetadataMeshId: %v", properties.GetNodeMetadataMeshId())
-	proxywasm.LogInfof(">> GetNodeMetadataName: %v", properties.GetNodeMetadataName())
-	proxywasm.LogInfof(">> GetNodeMetadataNamespace: %v", properties.GetNodeMetadataNamespace())
-	proxywasm.LogInfof(">> GetNodeMetadataNodeName: %v", properties.GetNodeMetadataNodeName())
-	proxywasm.LogInfof(">> GetNodeMetadataOwner: %v", properties.GetNodeMetadataOwner())
-	proxywasm.LogInfof(">> GetNodeMetadataPilotSan: %v", properties.GetNodeMetadataPilotSan())
-	proxywasm.LogInfof(">> GetNodeMetadataPodPorts: %v", properties.GetNodeMetadataPodPorts())
-	proxywasm.LogInfof(">> GetNodeMetadataServiceAccount: %v", properties.GetNodeMetadataServiceAccount())
-	proxywasm.LogInfof(">> GetNodeMetadataWorkloadName: %v", properties.GetNodeMetadataWorkloadName())
-}
-
-func printNodeProxyConfigProperties() {
-	proxywasm.LogInfof(">> GetNodeProxyConfigBinaryPath: %v", properties.GetNodeProxyConfigBinaryPath())
-	proxywasm.LogInfof(">> GetNodeProxyConfigConcurrency: %v", properties.GetNodeProxyConfigConcurrency())
-	proxywasm.LogInfof(">> GetNodeProxyConfigConfigPath: %v", properties.GetNodeProxyConfigConfigPath())
-	proxywasm.LogInfof(">> GetNodeProxyConfigControlPlaneAuthPolicy: %v", properties.GetNodeProxyConfigControlPlaneAuthPolicy())
-	proxywasm.LogInfof(">> GetNodeProxyConfigDiscoveryAddress: %v", properties.GetNodeProxyConfigDiscoveryAddress())
-	proxywasm.LogInfof(">> GetNodeProxyConfigDrainDuration: %v", properties.GetNodeProxyConfigDrainDuration())
-	proxywasm.LogInfof(">> GetNodeProxyConfigExtraStatTags: %v", properties.GetNodeProxyConfigExtraStatTags())
-	proxywasm.LogInfof(">> GetNodeProxyConfigHoldApplicationUntilProxyStarts: %v", properties.GetNodeProxyConfigHoldApplicationUntilProxyStarts())
-	proxywasm.LogInfof(">> GetNodeProxyConfigProxyAdminPort: %v", properties.GetNodeProxyConfigProxyAdminPort())
-	proxywasm.LogInfof(">> GetNodeProxyConfigProxyStatsMatcher: %v", properties.GetNodeProxyConfigProxyStatsMatcher())
-	proxywasm.LogInfof(">> GetNodeProxyConfigServiceCluster: %v", properties.GetNodeProxyConfigServiceCluster())
-	proxywasm.LogInfof(">> GetNodeProxyConfigStatNameLength: %v", properties.GetNodeProxyConfigStatNameLength())
-	proxywasm.LogInfof(">> GetNodeProxyConfigStatusPort: %v", properties.GetNodeProxyConfigStatusPort())
-	proxywasm.LogInfof(">> GetNodeProxyConfigTerminationDrainDuration: %v", properties.GetNodeProxyConfigTerminationDrainDuration())
-	proxywasm.LogInfof(">> GetNodeProxyConfigTracingZipkinAddress: %v", properties.GetNodeProxyConfigTracingZipkinAddress())
-}
-
-func printXdsProperties() {
-	proxywasm.LogInfof(">> GetXdsClusterName: %v", properties.GetXdsClusterName())
-	proxywasm.LogInfof(">> GetXdsClusterMetadata: %+v", properties.GetXdsClusterMetadata())
-	proxywasm.LogInfof(">> GetXdsRouteName: %v", properties.GetXdsRouteName())
-	proxywasm.LogInfof(">> GetXdsRouteMetadata: %+v", properties.GetXdsRouteMetadata())
-	proxywasm.LogInfof(">> GetXdsUpstreamHostMetadata: %+v", properties.GetXdsUpstreamHostMetadata())
-	proxywasm.LogInfof(">> GetXdsListenerFilterChainName: %v", properties.GetXdsListenerFilterChainName())
-}
-
-func printUpstreamProperties() {
-	proxywasm.LogInfof(">> GetUpstreamAddress: %v", properties.GetUpstreamAddress())
-	proxywasm.LogInfof(">> GetUpstreamPort: %v", properties.GetUpstreamPort())
-	proxywasm.LogInfof(">> GetUpstreamTlsVersion: %v", properties.GetUpstreamTlsVersion())
-	proxywasm.LogInfof(">> GetUpstreamSubjectLocalCertificate: %v", properties.GetUpstreamSubjectLocalCertificate())
-	proxywasm.LogInfof(">> GetUpstreamSubjectPeerCertificate: %v", properties.GetUpstreamSubjectPeerCertificate())
-	proxywasm.LogInfof(">> GetUpstreamDnsSanLocalCertificate: %v", properties.GetUpstreamDnsSanLocalCertificate())
-	proxywasm.LogInfof(">> GetUpstreamDnsSanPeerCertificate: %v", properties.GetUpstreamDnsSanPeerCertificate())
-	proxywasm.LogInfof(">> GetUpstreamUriSanLocalCertificate: %v", properties.GetUpstreamUriSanLocalCertificate())
-	proxywasm.LogInfof(">> GetUpstreamUriSanPeerCertificate: %v", properties.GetUpstreamUriSanPeerCertificate())
-	proxywasm.LogInfof(">> GetUpstreamSha256PeerCertificateDigest: %v", properties.GetUpstreamSha256PeerCertificateDigest())
-	proxywasm.LogInfof(">> GetUpstreamLocalAddress: %v", properties.GetUpstreamLocalAddress())
-	proxywasm.LogInfof(">> GetUpstreamTransportFailureReason: %v", properties.GetUpstreamTransportFailureReason())
-}
-
-func printConnectionProperties() {
-	proxywasm.LogInfof(">> GetDownstreamRemoteAddress: %v", properties.GetDownstreamRemoteAddress())
-	proxywasm.LogInfof(">> GetDownstreamRemotePort: %v", properties.GetDownstreamRemotePort())
-	proxywasm.LogInfof(">> GetDownstreamLocalAddress: %v", properties.GetDownstreamLocalAddress())
-	proxywasm.LogInfof(">> GetDownstreamLocalPort: %v", properties.GetDownstreamLocalPort())
-	proxywasm.LogInfof(">> GetDownstreamConnectionId: %v", properties.GetDownstreamConnectionId())
-	proxywasm.LogInfof(">> IsDownstreamConnectionTls: %v", properties.IsDownstreamConnectionTls())
-	proxywasm.LogInfof(">> GetDownstreamRequestedServerName: %v", properties.GetDownstreamRequestedServerName())
-	proxywasm.LogInfof(">> GetDownstreamTlsVersion: %v", properties.GetDownstreamTlsVersion())
-	proxywasm.LogInfof(">> GetDownstreamSubjectLocalCertificate: %v", properties.GetDownstreamSubjectLocalCertificate())
-	proxywasm.LogInfof(">> GetDownstreamSubjectPeerCertificate: %v", properties.GetDownstreamSubjectPeerCertificate())
-	proxywasm.LogInfof(">> GetDownstreamDnsSanLocalCertificate: %v", properties.GetDownstreamDnsSanLocalCertificate())
-	proxywasm.LogInfof(">> GetDownstreamDnsSanPeerCertificate: %v", properties.GetDownstreamDnsSanPeerCertificate())
-	proxywasm.LogInfof(">> GetDownstreamDnsSanPeerCertificate: %v", properties.GetDownstreamUriSanLocalCertificate())
-	proxywasm.LogInfof(">> GetDownstreamDnsSanPeerCertificate: %v", properties.GetDownstreamUriSanPeerCertificate())
-	proxywasm.LogInfof(">> GetDownstreamDnsSanPeerCertificate: %v", properties.GetDownstreamSha256PeerCertificateDigest())
-	proxywasm.LogInfof(">> GetDownstreamTerminationDetails: %v", properties.GetDownstreamTerminationDetails())
-}
-
-func printResponseProperties() {
-	proxywasm.LogInfof(">> GetResponseCode: %v", properties.GetResponseCode())
-	proxywasm.LogInfof(">> GetResponseCodeDetails: %v", properties.GetResponseCodeDetails())
-	proxywasm.LogInfof(">> GetResponseFlags: %v", properties.GetResponseFlags())
-	proxywasm.LogInfof(">> GetResponseGrpcStatusCode: %v", properties.GetResponseGrpcStatusCode())
-	proxywasm.LogInfof(">> GetResponseHeaders: %+v", properties.GetResponseHeaders())
-	proxywasm.LogInfof(">> GetResponseTrailers: %+v", properties.GetResponseTrailers())
-	proxywasm.LogInfof(">> GetResponseSize: %v", properties.GetResponseSize())
-	proxywasm.LogInfof(">> GetResponseTotalSize: %v", properties.GetResponseTotalSize())
-}
-
-func printRequestProperties() {
-	proxywasm.LogInfof(">> GetRequestPath: %v", properties.GetRequestPath())
-	proxywasm.LogInfof(">> GetRequestUrlPath: %v", properties.GetRequestUrlPath())
-	proxywasm.LogInfof(">> GetRequestHost: %v", properties.GetRequestHost())
-	proxywasm.LogInfof(">> GetRequestScheme: %v", properties.GetRequestScheme())
-	proxywasm.LogInfof(">> GetRequestMethod: %v", properties.GetRequestMethod())
-	proxywasm.LogInfof(">> GetRequestHeaders: %+v", properties.GetRequestHeaders())
-	proxywasm.LogInfof(">> GetRequestReferer: %v", properties.GetRequestReferer())
-	proxywasm.LogInfof(">> GetRequestUserAgent: %v", properties.GetRequestUserAgent())
-	proxywasm.LogInfof(">> GetRequestTime: %v", properties.GetRequestTime())
-	proxywasm.LogInfof(">> GetRequestId: %v", properties.GetRequestId())
-	proxywasm.LogInfof(">> GetRequestProtocol: %v", properties.GetRequestProtocol())
-	proxywasm.LogInfof(">> GetRequestQuery: %v", properties.GetRequestQuery())
-	proxywasm.LogInfof(">> GetRequestDuration: %v", properties.GetRequestDuration())
-	proxywasm.LogInfof(">> GetRequestSize: %v", properties.GetRequestSize())
-	proxywasm.LogInfof(">> GetRequestTotalSize: %v", properties.GetRequestTotalSize())
+	return dump
+}
+
+func collectWasmProperties(g *groupDump) {
+	g.add("GetPluginName", properties.GetPluginName())
+	g.add("GetPluginRootId", properties.GetPluginRootId())
+	g.add("GetPluginVmId", properties.GetPluginVmId())
+	g.add("GetClusterName", properties.GetClusterName())
+	g.add("GetRouteName", properties.GetRouteName())
+	g.add("GetListenerDirection", properties.GetListenerDirection())
+	g.add("GetNodeId", properties.GetNodeId())
+	g.add("GetNodeCluster", properties.GetNodeCluster())
+	g.add("GetNodeDynamicParams", properties.GetNodeDynamicParams())
+	g.add("GetNodeLocality", properties.GetNodeLocality())
+	g.add("GetNodeUserAgentName", properties.GetNodeUserAgentName())
+	g.add("GetNodeUserAgentVersion", properties.GetNodeUserAgentVersion())
+	g.add("GetNodeUserAgentBuildVersion", properties.GetNodeUserAgentBuildVersion())
+	g.add("GetNodeExtensions", properties.GetNodeExtensions())
+	g.add("GetNodeClientFeatures", properties.GetNodeClientFeatures())
+	g.add("GetNodeListeningAddresses", properties.GetNodeListeningAddresses())
+	g.add("GetClusterMetadata", properties.GetClusterMetadata())
+	g.add("GetListenerMetadata", properties.GetListenerMetadata())
+	g.add("GetRouteMetadata", properties.GetRouteMetadata())
+	g.add("GetUpstreamHostMetadata", properties.GetUpstreamHostMetadata())
+}
+
+func collectNodeMetadataProperties(g *groupDump) {
+	g.add("GetNodeMetadataAnnotations", properties.GetNodeMetadataAnnotations())
+	g.add("GetNodeMetadataAppContainers", properties.GetNodeMetadataAppContainers())
+	g.add("GetNodeMetadataClusterId", properties.GetNodeMetadataClusterId())
+	g.add("GetNodeMetadataEnvoyPrometheusPort", properties.GetNodeMetadataEnvoyPrometheusPort())
+	g.add("GetNodeMetadataEnvoyStatusPort", properties.GetNodeMetadataEnvoyStatusPort())
+	g.add("GetNodeMetadataInstanceIps", properties.GetNodeMetadataInstanceIps())
+	g.add("GetNodeMetadataInterceptionMode", properties.GetNodeMetadataInterceptionMode())
+	g.add("GetNodeMetadataIstioProxySha", properties.GetNodeMetadataIstioProxySha())
+	g.add("GetNodeMetadataIstioVersion", properties.GetNodeMetadataIstioVersion())
+	g.add("GetNodeMetadataLabels", properties.GetNodeMetadataLabels())
+	g.add("GetNodeMetadataMeshId", properties.GetNodeMetadataMeshId())
+	g.add("GetNodeMetadataName", properties.GetNodeMetadataName())
+	g.add("GetNodeMetadataNamespace", properties.GetNodeMetadataNamespace())
+	g.add("GetNodeMetadataNodeName", properties.GetNodeMetadataNodeName())
+	g.add("GetNodeMetadataOwner", properties.GetNodeMetadataOwner())
+	g.add("GetNodeMetadataPilotSan", properties.GetNodeMetadataPilotSan())
+	g.add("GetNodeMetadataPodPorts", properties.GetNodeMetadataPodPorts())
+	g.add("GetNodeMetadataServiceAccount", properties.GetNodeMetadataServiceAccount())
+	g.add("GetNodeMetadataWorkloadName", properties.GetNodeMetadataWorkloadName())
+}
+
+func collectNodeProxyConfigProperties(g *groupDump) {
+	g.add("GetNodeProxyConfig", properties.GetNodeProxyConfig())
+	g.add("GetNodeProxyConfigBinaryPath", properties.GetNodeProxyConfigBinaryPath())
+	g.add("GetNodeProxyConfigConcurrency", properties.GetNodeProxyConfigConcurrency())
+	g.add("GetNodeProxyConfigConfigPath", properties.GetNodeProxyConfigConfigPath())
+	g.add("GetNodeProxyConfigControlPlaneAuthPolicy", properties.GetNodeProxyConfigControlPlaneAuthPolicy())
+	g.add("GetNodeProxyConfigDiscoveryAddress", properties.GetNodeProxyConfigDiscoveryAddress())
+	g.add("GetNodeProxyConfigDrainDuration", properties.GetNodeProxyConfigDrainDuration())
+	g.add("GetNodeProxyConfigExtraStatTags", properties.GetNodeProxyConfigExtraStatTags())
+	g.add("GetNodeProxyConfigHoldApplicationUntilProxyStarts", properties.GetNodeProxyConfigHoldApplicationUntilProxyStarts())
+	g.add("GetNodeProxyConfigProxyAdminPort", properties.GetNodeProxyConfigProxyAdminPort())
+	g.add("GetNodeProxyConfigProxyStatsMatcher", properties.GetNodeProxyConfigProxyStatsMatcher())
+	g.add("GetNodeProxyConfigServiceCluster", properties.GetNodeProxyConfigServiceCluster())
+	g.add("GetNodeProxyConfigStatNameLength", properties.GetNodeProxyConfigStatNameLength())
+	g.add("GetNodeProxyConfigStatusPort", properties.GetNodeProxyConfigStatusPort())
+	g.add("GetNodeProxyConfigTerminationDrainDuration", properties.GetNodeProxyConfigTerminationDrainDuration())
+	g.add("GetNodeProxyConfigTracingDatadogAddress", properties.GetNodeProxyConfigTracingDatadogAddress())
+	g.add("GetNodeProxyConfigTracingOpenCensusAgentAddress", properties.GetNodeProxyConfigTracingOpenCensusAgentAddress())
+	g.add("GetNodeProxyConfigTracingZipkinAddress", properties.GetNodeProxyConfigTracingZipkinAddress())
+}
+
+func collectXdsProperties(g *groupDump) {
+	g.add("GetXdsClusterName", properties.GetXdsClusterName())
+	g.add("GetXdsClusterMetadata", properties.GetXdsClusterMetadata())
+	g.add("GetXdsRouteName", properties.GetXdsRouteName())
+	g.add("GetXdsRouteMetadata", properties.GetXdsRouteMetadata())
+	g.add("GetXdsUpstreamHostMetadata", properties.GetXdsUpstreamHostMetadata())
+	g.add("GetXdsListenerFilterChainName", properties.GetXdsListenerFilterChainName())
+	g.add("GetXdsListenerMetadata", properties.GetXdsListenerMetadata())
+	g.add("GetXdsListenerDirection", properties.GetXdsListenerDirection())
+	g.add("GetXdsVirtualHostName", properties.GetXdsVirtualHostName())
+	g.add("GetXdsVirtualHostMetadata", properties.GetXdsVirtualHostMetadata())
+	g.add("GetXdsNode", properties.GetXdsNode())
+}
+
+func collectUpstreamProperties(g *groupDump) {
+	g.add("GetUpstreamAddress", properties.GetUpstreamAddress())
+	g.add("GetUpstreamPort", properties.GetUpstreamPort())
+	g.add("GetUpstreamTlsVersion", properties.GetUpstreamTlsVersion())
+	g.add("GetUpstreamSubjectLocalCertificate", properties.GetUpstreamSubjectLocalCertificate())
+	g.add("GetUpstreamSubjectPeerCertificate", properties.GetUpstreamSubjectPeerCertificate())
+	g.add("GetUpstreamDnsSanLocalCertificate", properties.GetUpstreamDnsSanLocalCertificate())
+	g.add("GetUpstreamDnsSanPeerCertificate", properties.GetUpstreamDnsSanPeerCertificate())
+	g.add("GetUpstreamUriSanLocalCertificate", properties.GetUpstreamUriSanLocalCertificate())
+	g.add("GetUpstreamUriSanPeerCertificate", properties.GetUpstreamUriSanPeerCertificate())
+	g.add("GetUpstreamSha256PeerCertificateDigest", properties.GetUpstreamSha256PeerCertificateDigest())
+	g.add("GetUpstreamLocalAddress", properties.GetUpstreamLocalAddress())
+	g.add("GetUpstreamTransportFailureReason", properties.GetUpstreamTransportFailureReason())
+	g.add("GetUpstreamPeerSpiffeIdentity", properties.GetUpstreamPeerSpiffeIdentity())
+	g.add("GetUpstreamPeerMetadata", properties.GetUpstreamPeerMetadata())
+	g.add("GetUpstreamRequestAttemptCount", properties.GetUpstreamRequestAttemptCount())
+	g.add("GetUpstreamCxPoolReadyDuration", properties.GetUpstreamCxPoolReadyDuration())
+}
+
+func collectConnectionProperties(g *groupDump) {
+	g.add("GetDownstreamRemoteAddress", properties.GetDownstreamRemoteAddress())
+	g.add("GetDownstreamRemotePort", properties.GetDownstreamRemotePort())
+	g.add("GetDownstreamLocalAddress", properties.GetDownstreamLocalAddress())
+	g.add("GetDownstreamLocalPort", properties.GetDownstreamLocalPort())
+	g.add("GetDownstreamConnectionId", properties.GetDownstreamConnectionId())
+	g.add("IsDownstreamConnectionTls", properties.IsDownstreamConnectionTls())
+	g.add("GetDownstreamRequestedServerName", properties.GetDownstreamRequestedServerName())
+	g.add("GetDownstreamTlsVersion", properties.GetDownstreamTlsVersion())
+	g.add("GetDownstreamSubjectLocalCertificate", properties.GetDownstreamSubjectLocalCertificate())
+	g.add("GetDownstreamSubjectPeerCertificate", properties.GetDownstreamSubjectPeerCertificate())
+	g.add("GetDownstreamDnsSanLocalCertificate", properties.GetDownstreamDnsSanLocalCertificate())
+	g.add("GetDownstreamDnsSanPeerCertificate", properties.GetDownstreamDnsSanPeerCertificate())
+	g.add("GetDownstreamUriSanLocalCertificate", properties.GetDownstreamUriSanLocalCertificate())
+	g.add("GetDownstreamUriSanPeerCertificate", properties.GetDownstreamUriSanPeerCertificate())
+	g.add("GetDownstreamSha256PeerCertificateDigest", properties.GetDownstreamSha256PeerCertificateDigest())
+	g.add("GetDownstreamTerminationDetails", properties.GetDownstreamTerminationDetails())
+	g.add("GetDownstreamTransportFailureReason", properties.GetDownstreamTransportFailureReason())
+	g.add("GetDownstreamPeerSpiffeIdentity", properties.GetDownstreamPeerSpiffeIdentity())
+	g.add("GetDownstreamPeerMetadata", properties.GetDownstreamPeerMetadata())
+}
+
+func collectResponseProperties(g *groupDump) {
+	g.add("GetResponseCode", properties.GetResponseCode())
+	g.add("GetResponseCodeDetails", properties.GetResponseCodeDetails())
+	g.add("GetResponseFlags", properties.GetResponseFlags())
+	g.add("GetResponseGrpcStatusCode", properties.GetResponseGrpcStatusCode())
+	g.add("GetResponseHeaders", properties.GetResponseHeaders())
+	g.add("GetResponseTrailers", properties.GetResponseTrailers())
+	g.add("GetResponseSize", properties.GetResponseSize())
+	g.add("GetResponseTotalSize", properties.GetResponseTotalSize())
+	g.add("GetResponseBackendLatency", properties.GetResponseBackendLatency())
+}
+
+func collectRequestProperties(g *groupDump) {
+	g.add("GetRequestPath", properties.GetRequestPath())
+	g.add("GetRequestUrlPath", properties.GetRequestUrlPath())
+	g.add("GetRequestHost", properties.GetRequestHost())
+	g.add("GetRequestScheme", properties.GetRequestScheme())
+	g.add("GetRequestMethod", properties.GetRequestMethod())
+	g.add("GetRequestHeaders", properties.GetRequestHeaders())
+	g.add("GetRequestReferer", properties.GetRequestReferer())
+	g.add("GetRequestUserAgent", properties.GetRequestUserAgent())
+	g.add("GetRequestTime", properties.GetRequestTime())
+	g.add("GetRequestId", properties.GetRequestId())
+	g.add("GetRequestProtocol", properties.GetRequestProtocol())
+	g.add("GetRequestQuery", properties.GetRequestQuery())
+	g.add("GetRequestQueryParams", properties.GetRequestQueryParams())
+	g.add("GetRequestDuration", properties.GetRequestDuration())
+	g.add("GetRequestSize", properties.GetRequestSize())
+	g.add("GetRequestTotalSize", properties.GetRequestTotalSize())
 }
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// groupNames returns the names of the given property groups, in order.
+func groupNames(groups []propertyGroup) []string {
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.name)
+	}
+	return names
+}
+
+// mustParseConfigData parses the plugin configuration, failing the test on errors.
+func mustParseConfigData(t *testing.T, data []byte) pluginConfig {
+	t.Helper()
+	config, err := parseConfigData(data)
+	if err != nil {
+		t.Fatalf("failed to parse config %s: %v", data, err)
+	}
+	return config
+}
+
+// startTestPlugin starts the plugin with the given configuration in a host emulator.
+func startTestPlugin(t *testing.T, config string) (proxytest.HostEmulator, func()) {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().
+		WithVMContext(&vmContext{}).
+		WithPluginConfiguration([]byte(config))
+	host, reset := proxytest.NewHostEmulator(opt)
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusOK {
+		reset()
+		t.Fatalf("expected plugin start to succeed, got %v", status)
+	}
+	return host, reset
+}
+
+func TestParseConfigData(t *testing.T) {
+	// Every single event/group flag must enable exactly that group in exactly that phase.
+	for _, event := range pluginEvents {
+		for _, group := range propertyGroups {
+			t.Run(event.name+"."+group.name, func(t *testing.T) {
+				config := mustParseConfigData(t, []byte(fmt.Sprintf(`{"%v": {"%v": true}}`, event.name, group.name)))
+
+				if len(config.groups) != 1 {
+					t.Fatalf("expected 1 configured phase, got %v", len(config.groups))
+				}
+				names := groupNames(config.groups[event.phase])
+				if len(names) != 1 || names[0] != group.name {
+					t.Fatalf("expected [%v] in %v, got %v", group.name, event.phase, names)
+				}
+			})
+		}
+	}
+}
+
+func TestParseConfigDataMultipleGroups(t *testing.T) {
+	config := mustParseConfigData(t, []byte(`{
+		"onHttpRequestHeaders": {
+			"printRequestProperties": true,
+			"printUpstreamProperties": true,
+			"printConnectionProperties": false
+		},
+		"onHttpStreamDone": {
+			"printResponseProperties": true
+		},
+		"onUnknownEvent": {
+			"printWasmProperties": true
+		}
+	}`))
+
+	expected := map[properties.Phase][]string{
+		properties.PhaseHttpRequestHeaders: {"printUpstreamProperties", "printRequestProperties"},
+		properties.PhaseHttpStreamDone:     {"printResponseProperties"},
+	}
+	if len(config.groups) != len(expected) {
+		t.Fatalf("expected %v configured phases, got %v", len(expected), len(config.groups))
+	}
+	for phase, want := range expected {
+		got := groupNames(config.groups[phase])
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("expected %v in %v, got %v", want, phase, got)
+		}
+	}
+}
+
+func TestParseConfigDataEmpty(t *testing.T) {
+	if config := mustParseConfigData(t, []byte(`{}`)); len(config.groups) != 0 {
+		t.Fatalf("expected no configured phases, got %v", len(config.groups))
+	}
+}
+
+func TestOnPluginStartInvalidConfig(t *testing.T) {
+	opt := proxytest.NewEmulatorOption().
+		WithVMContext(&vmContext{}).
+		WithPluginConfiguration([]byte(`{"onPluginStart": `))
+	host, reset := proxytest.NewHostEmulator(opt)
+	defer reset()
+
+	if status := host.StartPlugin(); status != types.OnPluginStartStatusFailed {
+		t.Fatalf("expected plugin start to fail, got %v", status)
+	}
+}
+
+func TestParseConfigDataOutputFormat(t *testing.T) {
+	if format := mustParseConfigData(t, []byte(`{}`)).outputFormat; format != outputFormatText {
+		t.Errorf("expected default output format %v, got %v", outputFormatText, format)
+	}
+	if format := mustParseConfigData(t, []byte(`{"outputFormat": "json"}`)).outputFormat; format != outputFormatJson {
+		t.Errorf("expected output format %v, got %v", outputFormatJson, format)
+	}
+	if _, err := parseConfigData([]byte(`{"outputFormat": "yaml"}`)); err == nil {
+		t.Error("expected an error for an unknown output format")
+	}
+}
+
+func TestJsonOutput(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"outputFormat": "json",
+		"onHttpRequestHeaders": {
+			"printRequestProperties": true,
+			"printResponseProperties": true
+		}
+	}`)
+	defer reset()
+
+	if err := host.SetProperty([]string{"request", "path"}, []byte("/get?foo=bar")); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get?foo=bar"}}, false)
+
+	logs := host.GetInfoLogs()
+	if len(logs) != 1 {
+		t.Fatalf("expected a single log line, got %v", logs)
+	}
+
+	var dump struct {
+		ContextID  uint32                            `json:"context_id"`
+		Phase      string                            `json:"phase"`
+		Timestamp  string                            `json:"timestamp"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+		Skipped    []string                          `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(logs[0]), &dump); err != nil {
+		t.Fatalf("expected a JSON log line, got %v: %v", logs[0], err)
+	}
+	if dump.ContextID != contextID {
+		t.Errorf("expected context id %v, got %v", contextID, dump.ContextID)
+	}
+	if dump.Phase != properties.PhaseHttpRequestHeaders.String() {
+		t.Errorf("expected phase %v, got %v", properties.PhaseHttpRequestHeaders, dump.Phase)
+	}
+	if dump.Timestamp == "" {
+		t.Error("expected a timestamp")
+	}
+	if path := dump.Properties["request"]["GetRequestPath"]; path != "/get?foo=bar" {
+		t.Errorf("expected request path /get?foo=bar, got %v", path)
+	}
+	if fmt.Sprint(dump.Skipped) != "[response]" {
+		t.Errorf("expected the response group to be skipped, got %v", dump.Skipped)
+	}
+}
+
+func TestCollectNodeProxyConfigTracing(t *testing.T) {
+	host, reset := startTestPlugin(t, `{}`)
+	defer reset()
+
+	tracers := map[string]string{
+		"datadog":         "datadog-agent.sre.svc.cluster.local:8126",
+		"openCensusAgent": "dns://opencensus.istio-system:55678",
+		"zipkin":          "zipkin.istio-system:9411",
+	}
+	for tracer, address := range tracers {
+		path := []string{"node", "metadata", "PROXY_CONFIG", "tracing", tracer, "address"}
+		if err := host.SetProperty(path, []byte(address)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &groupDump{name: "printNodeProxyConfigProperties"}
+	collectNodeProxyConfigProperties(g)
+	printed := make(map[string]interface{}, len(g.properties))
+	for _, p := range g.properties {
+		printed[p.name] = p.value
+	}
+
+	want := map[string]string{
+		"GetNodeProxyConfigTracingDatadogAddress":         tracers["datadog"],
+		"GetNodeProxyConfigTracingOpenCensusAgentAddress": tracers["openCensusAgent"],
+		"GetNodeProxyConfigTracingZipkinAddress":          tracers["zipkin"],
+	}
+	for name, address := range want {
+		if got := printed[name]; got != address {
+			t.Errorf("%v: got %v, want %v", name, got, address)
+		}
+	}
+}
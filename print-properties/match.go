@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strings"
+
+	"print-properties/properties"
+
+	"github.com/tidwall/gjson"
+)
+
+// randFloat64 returns a pseudo-random number in [0.0,1.0), replaced in tests.
+var randFloat64 = rand.Float64
+
+// requestMatcher decides in OnHttpRequestHeaders whether the properties of a request get
+// printed. All configured filters need to match, unconfigured filters match any request.
+type requestMatcher struct {
+	sampleRate float64           // fraction of the matching requests to print, between 0 and 1
+	headers    map[string]string // header name to exact value, an empty value only requires presence
+	pathPrefix string
+	pathRegex  *regexp.Regexp
+	hosts      []string // allowed hosts, compared case-insensitively without port
+}
+
+// parseRequestMatcher parses the "match" section of the plugin configuration.
+//
+// Example configuration:
+//
+//	"match": {
+//		"sampleRate": 0.1,
+//		"headers": {"x-debug": "1"},
+//		"pathPrefix": "/api",
+//		"pathRegex": "^/api/v[0-9]+/",
+//		"hosts": ["httpbin.org"]
+//	}
+func parseRequestMatcher(jsonData gjson.Result) (requestMatcher, error) {
+	matcher := requestMatcher{
+		sampleRate: 1,
+		headers:    make(map[string]string),
+	}
+
+	if sampleRate := jsonData.Get("sampleRate"); sampleRate.Exists() {
+		matcher.sampleRate = sampleRate.Float()
+		if matcher.sampleRate < 0 || matcher.sampleRate > 1 {
+			return matcher, fmt.Errorf("sample rate %v is not between 0 and 1", sampleRate.Raw)
+		}
+	}
+
+	jsonData.Get("headers").ForEach(func(name, value gjson.Result) bool {
+		matcher.headers[strings.ToLower(name.String())] = value.String()
+		return true
+	})
+
+	matcher.pathPrefix = jsonData.Get("pathPrefix").String()
+
+	if pathRegex := jsonData.Get("pathRegex").String(); pathRegex != "" {
+		regex, err := regexp.Compile(pathRegex)
+		if err != nil {
+			return matcher, fmt.Errorf("invalid path regex %q: %v", pathRegex, err)
+		}
+		matcher.pathRegex = regex
+	}
+
+	for _, host := range jsonData.Get("hosts").Array() {
+		matcher.hosts = append(matcher.hosts, stripPort(strings.ToLower(host.String())))
+	}
+
+	return matcher, nil
+}
+
+// matches evaluates the filters against the request headers, sampling is applied last so the
+// sample rate applies to the requests that pass the other filters.
+func (m requestMatcher) matches(headers properties.Headers) bool {
+	for name, value := range m.headers {
+		if !headers.Has(name) || (value != "" && headers.Get(name) != value) {
+			return false
+		}
+	}
+
+	path := headers.Get(":path")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if !strings.HasPrefix(path, m.pathPrefix) {
+		return false
+	}
+	if m.pathRegex != nil && !m.pathRegex.MatchString(path) {
+		return false
+	}
+
+	if len(m.hosts) > 0 && !m.matchesHost(headers.Get(":authority")) {
+		return false
+	}
+
+	return m.sampleRate >= 1 || randFloat64() < m.sampleRate
+}
+
+func (m requestMatcher) matchesHost(authority string) bool {
+	host := stripPort(strings.ToLower(authority))
+	for _, allowed := range m.hosts {
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// stripPort removes the port from a host, taking IPv6 literals like [::1]:8080 into account.
+func stripPort(host string) string {
+	if strings.HasPrefix(host, "[") {
+		if i := strings.IndexByte(host, ']'); i >= 0 {
+			return host[:i+1]
+		}
+		return host
+	}
+	if strings.Count(host, ":") == 1 {
+		return host[:strings.IndexByte(host, ':')]
+	}
+	return host
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	"print-properties/properties"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestRequestMatcher(t *testing.T) {
+	headers := properties.Headers{
+		{":authority", "HttpBin.org:8080"},
+		{":path", "/api/v1/get?debug=true"},
+		{"X-Debug", "1"},
+	}
+
+	tests := []struct {
+		name   string
+		match  string
+		random float64
+		want   bool
+	}{
+		{"no filters", `{}`, 0.99, true},
+		{"header value", `{"headers": {"x-debug": "1"}}`, 0, true},
+		{"header value mismatch", `{"headers": {"x-debug": "0"}}`, 0, false},
+		{"header presence", `{"headers": {"x-debug": ""}}`, 0, true},
+		{"header missing", `{"headers": {"x-trace": ""}}`, 0, false},
+		{"path prefix", `{"pathPrefix": "/api/"}`, 0, true},
+		{"path prefix mismatch", `{"pathPrefix": "/admin"}`, 0, false},
+		{"path regex", `{"pathRegex": "^/api/v[0-9]+/get$"}`, 0, true},
+		{"path regex ignores query", `{"pathRegex": "debug"}`, 0, false},
+		{"host without port", `{"hosts": ["other.org", "httpbin.org"]}`, 0, true},
+		{"host mismatch", `{"hosts": ["other.org"]}`, 0, false},
+		{"sampled in", `{"sampleRate": 0.1}`, 0.05, true},
+		{"sampled out", `{"sampleRate": 0.1}`, 0.5, false},
+		{"sample rate zero", `{"sampleRate": 0}`, 0, false},
+		{"all filters", `{"sampleRate": 0.5, "headers": {"x-debug": "1"}, "pathPrefix": "/api", "hosts": ["httpbin.org"]}`, 0.2, true},
+	}
+
+	defer func(f func() float64) { randFloat64 = f }(randFloat64)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			randFloat64 = func() float64 { return tt.random }
+			matcher, err := parseRequestMatcher(gjson.Parse(tt.match))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matcher.matches(headers); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseRequestMatcherErrors(t *testing.T) {
+	for _, match := range []string{
+		`{"sampleRate": 1.5}`,
+		`{"sampleRate": -1}`,
+		`{"pathRegex": "("}`,
+	} {
+		if _, err := parseRequestMatcher(gjson.Parse(match)); err == nil {
+			t.Errorf("expected an error for %v", match)
+		}
+	}
+}
+
+func TestStripPort(t *testing.T) {
+	for host, want := range map[string]string{
+		"httpbin.org":      "httpbin.org",
+		"httpbin.org:8080": "httpbin.org",
+		"[::1]:8080":       "[::1]",
+		"[::1]":            "[::1]",
+		"::1":              "::1",
+	} {
+		if got := stripPort(host); got != want {
+			t.Errorf("expected %v for %v, got %v", want, host, got)
+		}
+	}
+}
+
+func TestMatchRememberedAcrossPhases(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"match": {"headers": {"x-debug": "1"}},
+		"onHttpResponseHeaders": {"printResponseProperties": true}
+	}`)
+	defer reset()
+
+	skipped := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(skipped, [][2]string{{":path", "/"}}, true)
+	host.CallOnResponseHeaders(skipped, [][2]string{{":status", "200"}}, false)
+	if logs := host.GetInfoLogs(); len(logs) != 0 {
+		t.Fatalf("expected no output for a request without debug header, got %v", logs)
+	}
+
+	matched := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(matched, [][2]string{{":path", "/"}, {"x-debug", "1"}}, true)
+	host.CallOnResponseHeaders(matched, [][2]string{{":status", "200"}}, false)
+	if logs := host.GetInfoLogs(); len(logs) == 0 || logs[0] != "********** OnHttpResponseHeaders **********" {
+		t.Fatalf("expected response properties for a request with debug header, got %v", logs)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"print-properties/properties"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+)
+
+// outputFormat defines how the collected properties are logged.
+type outputFormat string
+
+const (
+	// outputFormatText logs one line per property.
+	outputFormatText outputFormat = "text"
+	// outputFormatJson logs all properties of a phase as a single line JSON document.
+	outputFormatJson outputFormat = "json"
+)
+
+// parseOutputFormat validates the configured output format.
+func parseOutputFormat(format string) (outputFormat, error) {
+	switch outputFormat(format) {
+	case outputFormatText, outputFormatJson:
+		return outputFormat(format), nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// propertyDump holds the properties collected for all printed groups of a phase.
+type propertyDump struct {
+	contextID uint32
+	phase     properties.Phase
+	timestamp time.Time
+	groups    []*groupDump
+}
+
+// groupDump holds the properties collected for a single property group, in print order.
+type groupDump struct {
+	name       string
+	skipped    bool // the group is not available in the phase of the dump
+	properties []property
+}
+
+type property struct {
+	name  string
+	value interface{}
+}
+
+func newPropertyDump(contextID uint32, phase properties.Phase) *propertyDump {
+	return &propertyDump{
+		contextID: contextID,
+		phase:     phase,
+		timestamp: time.Now(),
+	}
+}
+
+// addGroup adds an empty group to the dump and returns it for collecting.
+func (d *propertyDump) addGroup(name string) *groupDump {
+	g := &groupDump{name: name}
+	d.groups = append(d.groups, g)
+	return g
+}
+
+// add records the value of a property.
+func (g *groupDump) add(name string, value interface{}) {
+	g.properties = append(g.properties, property{name: name, value: value})
+}
+
+// log writes the dump to the proxy log in the given output format.
+func (d *propertyDump) log(format outputFormat) {
+	switch format {
+	case outputFormatJson:
+		d.logJson()
+	default:
+		d.logText()
+	}
+}
+
+// logText logs a banner for the phase followed by one line per property.
+func (d *propertyDump) logText() {
+	proxywasm.LogInfof("********** %v **********", d.phase)
+	for _, g := range d.groups {
+		if g.skipped {
+			proxywasm.LogInfof(">> skipping %v: not available in %v", g.name, d.phase)
+			continue
+		}
+		for _, p := range g.properties {
+			proxywasm.LogInfof(">> %v: %+v", p.name, p.value)
+		}
+	}
+}
+
+// logJson logs the dump as a single line JSON document.
+//
+// Example output:
+//
+//	{"context_id":2,"phase":"OnHttpRequestHeaders","timestamp":"2023-10-18T09:12:43.115Z",
+//	 "properties":{"request":{"GetRequestPath":"/get",...}},"skipped":["response"]}
+func (d *propertyDump) logJson() {
+	data, err := json.Marshal(d.toJson())
+	if err != nil {
+		proxywasm.LogErrorf("failed to marshal properties of %v: %v", d.phase, err)
+		return
+	}
+	proxywasm.LogInfo(string(data))
+}
+
+// toJson converts the dump into a JSON serializable document, with the groups keyed by their
+// short name (e.g. "nodeMetadata" for printNodeMetadataProperties).
+func (d *propertyDump) toJson() map[string]interface{} {
+	groups := make(map[string]interface{})
+	skipped := make([]string, 0)
+	for _, g := range d.groups {
+		if g.skipped {
+			skipped = append(skipped, groupKey(g.name))
+			continue
+		}
+		values := make(map[string]interface{})
+		for _, p := range g.properties {
+			values[p.name] = jsonValue(p.value)
+		}
+		groups[groupKey(g.name)] = values
+	}
+
+	return map[string]interface{}{
+		"context_id": d.contextID,
+		"phase":      d.phase.String(),
+		"timestamp":  d.timestamp.UTC().Format(time.RFC3339Nano),
+		"properties": groups,
+		"skipped":    skipped,
+	}
+}
+
+// groupKey strips the print prefix and Properties suffix of a group name and lower-cases the
+// first letter (e.g. printNodeMetadataProperties becomes nodeMetadata).
+func groupKey(name string) string {
+	key := strings.TrimSuffix(strings.TrimPrefix(name, "print"), "Properties")
+	if key == "" {
+		return name
+	}
+	return strings.ToLower(key[:1]) + key[1:]
+}
+
+// jsonValue converts property values that have a meaningful string representation (e.g.
+// durations, traffic direction or response flags) into that string, so they end up readable
+// rather than as raw numbers in the JSON document.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case diffChange:
+		return map[string]interface{}{"from": jsonValue(v.from), "to": jsonValue(v.to)}
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
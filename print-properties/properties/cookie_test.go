@@ -0,0 +1,48 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCookies(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   Cookies
+	}{
+		{"empty", "", Cookies{}},
+		{"single", "session=abc", Cookies{{"session", "abc"}}},
+		{"semicolon and whitespace", " a=1 ;b=2;  c = 3 ", Cookies{{"a", "1"}, {"b", "2"}, {"c", "3"}}},
+		{"repeated names keep order", "id=path; id=root", Cookies{{"id", "path"}, {"id", "root"}}},
+		{"empty value", "a=; b", Cookies{{"a", ""}, {"b", ""}}},
+		{"empty entries", ";;a=1;", Cookies{{"a", "1"}}},
+		{"quoted value", `theme="dark mode"; q=""`, Cookies{{"theme", "dark mode"}, {"q", ""}}},
+		{"single quote kept", `a="; b=x"`, Cookies{{"a", `"`}, {"b", `x"`}}},
+		{"value with equal sign", "token=YWJj==", Cookies{{"token", "YWJj=="}}},
+		{"no percent decoding", "q=a%20b", Cookies{{"q", "a%20b"}}},
+		{"missing name", "=v; a=1", Cookies{{"a", "1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseCookies(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCookies(%q): got %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCookies(t *testing.T) {
+	cookies := ParseCookies("id=path; session=abc; id=root")
+
+	if got := cookies.Get("id"); got != "path" {
+		t.Errorf("Get(id): got %q", got)
+	}
+	if got := cookies.Values("id"); !reflect.DeepEqual(got, []string{"path", "root"}) {
+		t.Errorf("Values(id): got %q", got)
+	}
+	if !cookies.Has("session") || cookies.Has("missing") || cookies.Get("missing") != "" {
+		t.Errorf("unexpected cookies %q", cookies)
+	}
+}
@@ -0,0 +1,57 @@
+// Helper function to read and write filter state objects
+// https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#metadata-and-filter-state
+// https://www.envoyproxy.io/docs/envoy/latest/configuration/advanced/well_known_filter_state
+package properties
+
+import (
+	"fmt"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+)
+
+// Prefix envoy adds to the filter state key of every property set by a wasm plugin
+const wasmFilterStatePrefix = "wasm."
+
+// Get the serialized value of a filter state object
+//
+// Example: GetFilterState("envoy.network.upstream_server_name")
+func GetFilterState(key string) []byte {
+	filterState, err := getProperty([]string{"filter_state", key})
+	if err != nil {
+		logPropertyWarnf("failed reading filter state attribute filter_state.%v: %v", key, err)
+		return []byte{}
+	}
+	return filterState
+}
+
+// Get the serialized value of a filter state object as a string
+func GetFilterStateString(key string) string {
+	return string(GetFilterState(key))
+}
+
+// Get the value of a filter state object previously set by a wasm plugin through
+// SetFilterState. The key is given without the "wasm." prefix
+func GetWasmFilterState(key string) []byte {
+	return GetFilterState(wasmFilterStatePrefix + key)
+}
+
+// Get the value of a filter state object previously set by a wasm plugin through
+// SetFilterState as a string. The key is given without the "wasm." prefix
+func GetWasmFilterStateString(key string) string {
+	return string(GetWasmFilterState(key))
+}
+
+// Set a filter state object, so it can be consumed by later filters and access loggers.
+// Envoy stores the value under the "wasm.<key>" filter state key, which makes it available
+// to access logs as %FILTER_STATE(wasm.<key>:PLAIN)%
+func SetFilterState(key string, value []byte) error {
+	if err := proxywasm.SetProperty([]string{key}, value); err != nil {
+		return fmt.Errorf("failed setting filter state %v%v: %v", wasmFilterStatePrefix, key, err)
+	}
+	return nil
+}
+
+// Set a filter state object with a string value
+func SetFilterStateString(key string, value string) error {
+	return SetFilterState(key, []byte(value))
+}
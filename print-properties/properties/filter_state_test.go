@@ -0,0 +1,33 @@
+//go:build proxytest
+
+package properties
+
+import "testing"
+
+func TestSetFilterStateRoundTrip(t *testing.T) {
+	host, reset := newSnapshotHost(t, `{}`)
+	defer reset()
+
+	if err := SetFilterStateString("correlation", "req-1"); err != nil {
+		t.Fatal(err)
+	}
+	value, err := host.GetProperty([]string{"correlation"})
+	if err != nil {
+		t.Fatalf("expected the plugin to set property correlation: %v", err)
+	}
+	if string(value) != "req-1" {
+		t.Fatalf("correlation: got %q", value)
+	}
+
+	// envoy exposes properties set by a wasm plugin as filter state under the "wasm." prefix
+	setStringProperty(t, host, []string{"filter_state", "wasm.correlation"}, string(value))
+	if got := GetWasmFilterStateString("correlation"); got != "req-1" {
+		t.Errorf("GetWasmFilterStateString: got %q", got)
+	}
+	if got := GetFilterStateString("wasm.correlation"); got != "req-1" {
+		t.Errorf("GetFilterStateString: got %q", got)
+	}
+	if got := GetWasmFilterStateString("missing"); got != "" {
+		t.Errorf("expected an empty value for a missing key, got %q", got)
+	}
+}
@@ -1,11 +1,7 @@
 // Helper function and structs to parse istio filter metadata
 package properties
 
-import (
-	"strings"
-
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
+import "strings"
 
 // Metadata provides additional inputs to filters based on matched listeners,
 // filter chains, routes and endpoints. It is structured as a map, usually from
@@ -47,7 +43,7 @@ func getIstioFilterMetadata(path []string) IstioFilterMetadata {
 
 	config, err := getPropertyString(append(path, "config"))
 	if err != nil {
-		proxywasm.LogWarnf("failed reading configuration attribute %v.config: %v", strings.Join(path, "."), err)
+		logPropertyWarnf("failed reading configuration attribute %v.config: %v", strings.Join(path, "."), err)
 		result.Config = ""
 	} else {
 		result.Config = config
@@ -55,7 +51,7 @@ func getIstioFilterMetadata(path []string) IstioFilterMetadata {
 
 	services, err := getPropertyByteSliceSlice(append(path, "services"))
 	if err != nil {
-		proxywasm.LogWarnf("failed reading configuration attribute %v.services: %v", strings.Join(path, "."), err)
+		logPropertyWarnf("failed reading configuration attribute %v.services: %v", strings.Join(path, "."), err)
 	}
 
 	for _, service := range services {
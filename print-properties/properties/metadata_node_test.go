@@ -0,0 +1,114 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/proxytest"
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+// Create a host emulator with the given PROXY_CONFIG node metadata loaded
+func newProxyConfigHost(t *testing.T, proxyConfig string) func() {
+	t.Helper()
+	opt := proxytest.NewEmulatorOption().WithVMContext(&types.DefaultVMContext{})
+	host, reset := proxytest.NewHostEmulator(opt)
+	if err := LoadFixture(host, []byte(`{"node": {"metadata": {"PROXY_CONFIG": `+proxyConfig+`}}}`)); err != nil {
+		reset()
+		t.Fatal(err)
+	}
+	return reset
+}
+
+// Istio marshals the ProxyConfig tracers with their json names, e.g. openCensusAgent
+func TestProxyConfigTracers(t *testing.T) {
+	reset := newProxyConfigHost(t, `{
+		"tracing": {
+			"sampling": 1,
+			"openCensusAgent": {"address": "dns://opencensus.istio-system:55678"}
+		}
+	}`)
+	defer reset()
+
+	if got := GetNodeProxyConfigTracingOpenCensusAgentAddress(); got != "dns://opencensus.istio-system:55678" {
+		t.Errorf("node.metadata.PROXY_CONFIG.tracing.openCensusAgent.address: got %q", got)
+	}
+	tracing := GetNodeProxyConfig().Tracing
+	if tracing.OpenCensusAgentAddress != "dns://opencensus.istio-system:55678" || tracing.SampleRate != 1 {
+		t.Errorf("node.metadata.PROXY_CONFIG.tracing: got %+v", tracing)
+	}
+}
+
+// Depending on the envoy/istio version, protobuf struct numbers and bools are encoded natively
+// (float64, single byte) or as strings
+func TestProxyConfigEncodings(t *testing.T) {
+	tests := []struct {
+		name        string
+		proxyConfig string
+	}{
+		{"native", `{"concurrency": 2, "statusPort": 15020, "holdApplicationUntilProxyStarts": true}`},
+		{"string", `{"concurrency": "2", "statusPort": "15020", "holdApplicationUntilProxyStarts": "true"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset := newProxyConfigHost(t, tt.proxyConfig)
+			defer reset()
+
+			if got := GetNodeProxyConfigConcurrency(); got != 2 {
+				t.Errorf("node.metadata.PROXY_CONFIG.concurrency: got %v", got)
+			}
+			if got := GetNodeProxyConfigStatusPort(); got != 15020 {
+				t.Errorf("node.metadata.PROXY_CONFIG.statusPort: got %v", got)
+			}
+			if got := GetNodeProxyConfigHoldApplicationUntilProxyStarts(); !got {
+				t.Errorf("node.metadata.PROXY_CONFIG.holdApplicationUntilProxyStarts: got %v", got)
+			}
+
+			proxyConfig := GetNodeProxyConfig()
+			if proxyConfig.Concurrency != 2 || proxyConfig.StatusPort != 15020 || !proxyConfig.HoldApplicationUntilProxyStarts {
+				t.Errorf("node.metadata.PROXY_CONFIG: got %+v", proxyConfig)
+			}
+		})
+	}
+}
+
+func TestDeserializeNumberAndBool(t *testing.T) {
+	numbers := []struct {
+		data    []byte
+		want    float64
+		wantErr bool
+	}{
+		{SerializeFloat64(15090), 15090, false},
+		{[]byte("15090"), 15090, false},
+		{[]byte("0.25"), 0.25, false},
+		{[]byte("abc"), 0, true},
+		{[]byte{}, 0, true},
+	}
+	for _, tt := range numbers {
+		got, err := deserializeToNumber(tt.data)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("deserializeToNumber(%q): got %v (%v)", tt.data, got, err)
+		}
+	}
+
+	bools := []struct {
+		data    []byte
+		want    bool
+		wantErr bool
+	}{
+		{[]byte{1}, true, false},
+		{[]byte{0}, false, false},
+		{[]byte("true"), true, false},
+		{[]byte("false"), false, false},
+		{[]byte("yes"), false, true},
+		{[]byte{}, false, true},
+	}
+	for _, tt := range bools {
+		got, err := deserializeToBool(tt.data)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("deserializeToBool(%q): got %v (%v)", tt.data, got, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Helper function to retreive peer workload metadata exchanged by istio
+// https://istio.io/latest/docs/reference/config/proxy_extensions/metadata_exchange/
+// https://github.com/istio/proxy/tree/master/source/extensions/filters/http/peer_metadata
+package properties
+
+import "strings"
+
+// Workload metadata of the remote side of the connection, as discovered by the istio
+// metadata exchange. These mirror the GetNodeMetadata* family for the local side
+type PeerMetadata struct {
+	WorkloadName    string
+	WorkloadType    string
+	InstanceName    string
+	Namespace       string
+	ClusterId       string
+	ServiceName     string
+	ServiceRevision string
+	AppName         string
+	AppVersion      string
+	Labels          map[string]string
+}
+
+// Get the workload metadata of the downstream peer (the client of this proxy)
+func GetDownstreamPeerMetadata() PeerMetadata {
+	return getPeerMetadata("downstream_peer")
+}
+
+// Get the workload metadata of the upstream peer (the server this proxy forwards to)
+func GetUpstreamPeerMetadata() PeerMetadata {
+	return getPeerMetadata("upstream_peer")
+}
+
+// Helper function to read the peer metadata object stored by the istio metadata exchange
+// filter under the given filter state key
+//
+// Example envoy filter state:
+//
+//	"downstream_peer": {
+//		"workload": "app-a",
+//		"type": "deployment",
+//		"name": "app-a-6d78c67d85-qsbtz",
+//		"namespace": "ns-a",
+//		"cluster": "Kubernetes",
+//		"service": "app-a",
+//		"revision": "v1",
+//		"app": "app-a",
+//		"version": "v1",
+//		"labels": {
+//			"app": "app-a",
+//			"version": "v1"
+//		}
+//	}
+func getPeerMetadata(key string) PeerMetadata {
+	result := PeerMetadata{}
+
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"workload", &result.WorkloadName},
+		{"type", &result.WorkloadType},
+		{"name", &result.InstanceName},
+		{"namespace", &result.Namespace},
+		{"cluster", &result.ClusterId},
+		{"service", &result.ServiceName},
+		{"revision", &result.ServiceRevision},
+		{"app", &result.AppName},
+		{"version", &result.AppVersion},
+	}
+	for _, field := range fields {
+		path := []string{"filter_state", key, field.name}
+		value, err := getPropertyString(path)
+		if err != nil {
+			logPropertyWarnf("failed reading peer metadata attribute %v: %v", strings.Join(path, "."), err)
+		}
+		*field.value = value
+	}
+
+	labels, err := getPropertyStringMap([]string{"filter_state", key, "labels"})
+	if err != nil {
+		logPropertyWarnf("failed reading peer metadata attribute filter_state.%v.labels: %v", key, err)
+		labels = make(map[string]string)
+	}
+	result.Labels = labels
+
+	return result
+}
@@ -0,0 +1,71 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeerMetadata(t *testing.T) {
+	_, reset := newSnapshotHost(t, `{
+		"filter_state": {
+			"downstream_peer": {
+				"workload": "sleep",
+				"type": "deployment",
+				"name": "sleep-7656cf8794-r2t8k",
+				"namespace": "ns-a",
+				"cluster": "Kubernetes",
+				"service": "sleep",
+				"revision": "v1",
+				"app": "sleep",
+				"version": "v1",
+				"labels": {"app": "sleep", "version": "v1"}
+			},
+			"upstream_peer": {
+				"workload": "httpbin",
+				"name": "httpbin-7b5f8d8c9d-x2x6n",
+				"namespace": "ns-b",
+				"labels": {"app": "httpbin"}
+			}
+		}
+	}`)
+	defer reset()
+
+	wantDownstream := PeerMetadata{
+		WorkloadName:    "sleep",
+		WorkloadType:    "deployment",
+		InstanceName:    "sleep-7656cf8794-r2t8k",
+		Namespace:       "ns-a",
+		ClusterId:       "Kubernetes",
+		ServiceName:     "sleep",
+		ServiceRevision: "v1",
+		AppName:         "sleep",
+		AppVersion:      "v1",
+		Labels:          map[string]string{"app": "sleep", "version": "v1"},
+	}
+	if got := GetDownstreamPeerMetadata(); !reflect.DeepEqual(got, wantDownstream) {
+		t.Errorf("GetDownstreamPeerMetadata(): got %+v, want %+v", got, wantDownstream)
+	}
+
+	// fields missing from the exchanged metadata are left empty
+	wantUpstream := PeerMetadata{
+		WorkloadName: "httpbin",
+		InstanceName: "httpbin-7b5f8d8c9d-x2x6n",
+		Namespace:    "ns-b",
+		Labels:       map[string]string{"app": "httpbin"},
+	}
+	if got := GetUpstreamPeerMetadata(); !reflect.DeepEqual(got, wantUpstream) {
+		t.Errorf("GetUpstreamPeerMetadata(): got %+v, want %+v", got, wantUpstream)
+	}
+}
+
+func TestPeerMetadataMissing(t *testing.T) {
+	_, reset := newSnapshotHost(t, `{}`)
+	defer reset()
+
+	want := PeerMetadata{Labels: map[string]string{}}
+	if got := GetDownstreamPeerMetadata(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected empty peer metadata without metadata exchange, got %+v", got)
+	}
+}
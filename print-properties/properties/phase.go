@@ -0,0 +1,199 @@
+// Helper functions to check which attributes are available in which plugin phase
+// https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
+)
+
+// Plugin callback during which properties are read
+type Phase int
+
+const (
+	PhaseUnknown Phase = iota
+	PhasePluginStart
+	PhaseHttpRequestHeaders
+	PhaseHttpRequestBody
+	PhaseHttpRequestTrailers
+	PhaseHttpResponseHeaders
+	PhaseHttpResponseBody
+	PhaseHttpResponseTrailers
+	PhaseHttpStreamDone
+	PhaseTick
+	PhaseTcpNewConnection
+	PhaseTcpDownstreamData
+	PhaseTcpUpstreamData
+	PhaseTcpDownstreamClose
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseUnknown:
+		return "Unknown"
+	case PhasePluginStart:
+		return "OnPluginStart"
+	case PhaseHttpRequestHeaders:
+		return "OnHttpRequestHeaders"
+	case PhaseHttpRequestBody:
+		return "OnHttpRequestBody"
+	case PhaseHttpRequestTrailers:
+		return "OnHttpRequestTrailers"
+	case PhaseHttpResponseHeaders:
+		return "OnHttpResponseHeaders"
+	case PhaseHttpResponseBody:
+		return "OnHttpResponseBody"
+	case PhaseHttpResponseTrailers:
+		return "OnHttpResponseTrailers"
+	case PhaseHttpStreamDone:
+		return "OnHttpStreamDone"
+	case PhaseTick:
+		return "OnTick"
+	case PhaseTcpNewConnection:
+		return "OnNewConnection"
+	case PhaseTcpDownstreamData:
+		return "OnDownstreamData"
+	case PhaseTcpUpstreamData:
+		return "OnUpstreamData"
+	case PhaseTcpDownstreamClose:
+		return "OnDownstreamClose"
+	}
+	return "UNKNOWN"
+}
+
+// Error returned (wrapped) when reading an attribute that is not available in the current phase
+var ErrNotAvailableInPhase = errors.New("not available in this phase")
+
+type phaseError struct {
+	attribute string
+	phase     Phase
+}
+
+func (e *phaseError) Error() string {
+	return fmt.Sprintf("attribute %v is %v (%v)", e.attribute, ErrNotAvailableInPhase, e.phase)
+}
+
+func (e *phaseError) Unwrap() error {
+	return ErrNotAvailableInPhase
+}
+
+// Availability of an attribute, identified by its dotted path prefix
+type AttributeAvailability struct {
+	Prefix string
+	Phases []Phase
+}
+
+var (
+	allHttpPhases = []Phase{
+		PhaseHttpRequestHeaders, PhaseHttpRequestBody, PhaseHttpRequestTrailers,
+		PhaseHttpResponseHeaders, PhaseHttpResponseBody, PhaseHttpResponseTrailers,
+		PhaseHttpStreamDone,
+	}
+	responseHttpPhases = []Phase{
+		PhaseHttpResponseHeaders, PhaseHttpResponseBody, PhaseHttpResponseTrailers,
+		PhaseHttpStreamDone,
+	}
+	allTcpPhases = []Phase{
+		PhaseTcpNewConnection, PhaseTcpDownstreamData, PhaseTcpUpstreamData, PhaseTcpDownstreamClose,
+	}
+	upstreamTcpPhases = []Phase{PhaseTcpUpstreamData, PhaseTcpDownstreamClose}
+	allStreamPhases   = append(append([]Phase{}, allHttpPhases...), allTcpPhases...)
+	allPhases         = append([]Phase{PhasePluginStart, PhaseTick}, allStreamPhases...)
+)
+
+// Table of attribute availability per phase. The longest matching prefix wins, attributes
+// without a matching entry are considered available in every phase
+var AttributeAvailabilityTable = []AttributeAvailability{
+	{"request", allHttpPhases},
+	{"request.duration", []Phase{PhaseHttpStreamDone}},
+	{"response", responseHttpPhases},
+	{"response.trailers", []Phase{PhaseHttpResponseTrailers, PhaseHttpStreamDone}},
+	{"source", allStreamPhases},
+	{"destination", allStreamPhases},
+	{"connection", allStreamPhases},
+	{"upstream", append(append([]Phase{}, responseHttpPhases...), upstreamTcpPhases...)},
+	{"metadata", allStreamPhases},
+	{"filter_state", allStreamPhases},
+	{"xds", allStreamPhases},
+	{"xds.node", allPhases},
+	{"xds.route_name", allHttpPhases},
+	{"xds.route_metadata", allHttpPhases},
+	{"xds.virtual_host_name", allHttpPhases},
+	{"xds.virtual_host_metadata", allHttpPhases},
+	{"node", allPhases},
+	{"plugin_name", allPhases},
+	{"plugin_root_id", allPhases},
+	{"plugin_vm_id", allPhases},
+	{"cluster_name", allHttpPhases},
+	{"cluster_metadata", allHttpPhases},
+	{"listener_direction", allPhases},
+	{"listener_metadata", allStreamPhases},
+	{"route_name", responseHttpPhases},
+	{"route_metadata", allHttpPhases},
+	{"upstream_host_metadata", responseHttpPhases},
+}
+
+// Phase of the callback currently being executed
+var currentPhase = PhaseUnknown
+
+// Set the phase of the callback currently being executed. Plugins call this at the start of
+// every callback to enable phase checks, as long as no phase is set all attributes are read
+func SetPhase(phase Phase) {
+	currentPhase = phase
+}
+
+// Get the phase of the callback currently being executed
+func GetPhase() Phase {
+	return currentPhase
+}
+
+// Check whether an attribute, given as dotted path (e.g. "response.code") or path prefix
+// (e.g. "upstream"), is available in a phase. Everything is available in PhaseUnknown
+func IsAvailable(phase Phase, attribute string) bool {
+	if phase == PhaseUnknown {
+		return true
+	}
+	var match *AttributeAvailability
+	for i, entry := range AttributeAvailabilityTable {
+		if attribute != entry.Prefix && !strings.HasPrefix(attribute, entry.Prefix+".") {
+			continue
+		}
+		if match == nil || len(entry.Prefix) > len(match.Prefix) {
+			match = &AttributeAvailabilityTable[i]
+		}
+	}
+	if match == nil {
+		return true
+	}
+	for _, p := range match.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// Get a property from the host, failing early when the attribute is not available in the
+// current phase
+func getProperty(path []string) ([]byte, error) {
+	attribute := strings.Join(path, ".")
+	if !IsAvailable(currentPhase, attribute) {
+		return nil, &phaseError{attribute: attribute, phase: currentPhase}
+	}
+	return proxywasm.GetProperty(path)
+}
+
+// Log a failure to read a property. Attributes that are not available in the current phase
+// are expected and therefore logged at debug level instead of warning level
+func logPropertyWarnf(format string, args ...interface{}) {
+	for _, arg := range args {
+		if err, ok := arg.(error); ok && errors.Is(err, ErrNotAvailableInPhase) {
+			proxywasm.LogDebugf(format, args...)
+			return
+		}
+	}
+	proxywasm.LogWarnf(format, args...)
+}
@@ -0,0 +1,43 @@
+package properties
+
+import "testing"
+
+func TestIsAvailable(t *testing.T) {
+	tests := []struct {
+		phase     Phase
+		attribute string
+		want      bool
+	}{
+		{PhaseUnknown, "response.code", true},
+		{PhaseHttpRequestHeaders, "request.headers", true},
+		{PhaseHttpRequestHeaders, "response.code", false},
+		{PhaseHttpResponseHeaders, "response.code", true},
+		{PhaseHttpRequestHeaders, "request.duration", false},
+		{PhaseHttpStreamDone, "request.duration", true},
+		{PhaseTcpNewConnection, "request.headers", false},
+		{PhaseTcpNewConnection, "upstream.address", false},
+		{PhaseTcpUpstreamData, "upstream.address", true},
+		{PhasePluginStart, "node.id", true},
+		{PhasePluginStart, "source.address", false},
+
+		// listener and cluster level xds attributes exist for network filters as well
+		{PhaseTcpNewConnection, "listener_direction", true},
+		{PhaseTcpNewConnection, "xds.listener_metadata", true},
+		{PhaseTcpDownstreamData, "xds.cluster_name", true},
+		{PhaseTcpNewConnection, "xds.node", true},
+		{PhaseTcpNewConnection, "xds.route_name", false},
+		{PhaseHttpRequestHeaders, "xds.route_name", true},
+		{PhaseTcpNewConnection, "xds.virtual_host_metadata.filter_metadata.istio", false},
+		{PhaseTcpNewConnection, "listener_metadata", true},
+		{PhasePluginStart, "xds.cluster_name", false},
+		{PhaseTick, "xds.listener_metadata", false},
+		{PhasePluginStart, "xds.node", true},
+		{PhaseTick, "listener_metadata", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsAvailable(tt.phase, tt.attribute); got != tt.want {
+			t.Errorf("IsAvailable(%v, %q): got %v, want %v", tt.phase, tt.attribute, got, tt.want)
+		}
+	}
+}
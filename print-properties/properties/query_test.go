@@ -0,0 +1,50 @@
+package properties
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  QueryParams
+	}{
+		{"empty", "", QueryParams{}},
+		{"leading question mark", "?a=1&b=2", QueryParams{"a": {"1"}, "b": {"2"}}},
+		{"repeated keys keep order", "tag=b&tag=a&tag=b", QueryParams{"tag": {"b", "a", "b"}}},
+		{"empty values", "a=&b", QueryParams{"a": {""}, "b": {""}}},
+		{"empty pairs", "&&a=1&", QueryParams{"a": {"1"}}},
+		{"value with equal sign", "token=YWJj==", QueryParams{"token": {"YWJj=="}}},
+		{"percent-encoding", "q=caf%C3%A9%20au%2Blait&%6Eame=x", QueryParams{"q": {"café au+lait"}, "name": {"x"}}},
+		{"plus as space", "q=hello+world", QueryParams{"q": {"hello world"}}},
+		{"invalid escapes kept", "q=100%&r=%zz&s=%4", QueryParams{"q": {"100%"}, "r": {"%zz"}, "s": {"%4"}}},
+		{"empty name", "=v", QueryParams{"": {"v"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseQuery(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q): got %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams(t *testing.T) {
+	params := ParseQuery("tag=a&tag=b&flag")
+
+	if got := params.Get("tag"); got != "a" {
+		t.Errorf("Get(tag): got %q", got)
+	}
+	if got := params.Values("tag"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Values(tag): got %q", got)
+	}
+	if !params.Has("flag") || params.Get("flag") != "" {
+		t.Errorf("expected flag to be present without value, got %q", params["flag"])
+	}
+	if params.Has("missing") || params.Get("missing") != "" || len(params.Values("missing")) != 0 {
+		t.Error("expected missing to be absent")
+	}
+}
@@ -0,0 +1,83 @@
+//go:build proxytest
+
+package properties
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSerializeRoundTrip(t *testing.T) {
+	if got := deserializeToUint64(SerializeUint64(15090)); got != 15090 {
+		t.Errorf("uint64: got %v", got)
+	}
+	if got := deserializeToFloat64(SerializeFloat64(0.25)); got != 0.25 {
+		t.Errorf("float64: got %v", got)
+	}
+	if got, err := deserializeToBool(SerializeBool(true)); err != nil || !got {
+		t.Errorf("bool: got %v (%v)", got, err)
+	}
+
+	timestamp := time.Date(2023, 10, 18, 9, 12, 43, 115000000, time.UTC)
+	if got := deserializeToTimestamp(SerializeTimestamp(timestamp)); !got.Equal(timestamp) {
+		t.Errorf("timestamp: got %v", got)
+	}
+	if got := deserializeToDuration(SerializeDuration(1500 * time.Microsecond)); got != 1500*time.Microsecond {
+		t.Errorf("duration: got %v", got)
+	}
+
+	strs := []string{"istio-proxy", "", "spiffe://cluster.local/ns/default/sa/httpbin"}
+	if got := deserializeToStringSlice(SerializeStringSlice(strs)); !reflect.DeepEqual(got, strs) {
+		t.Errorf("string slice: got %q", got)
+	}
+	bss := [][]byte{[]byte("a"), []byte("bc")}
+	if got := deserializeToByteSliceSlice(SerializeByteSliceSlice(bss)); !reflect.DeepEqual(got, bss) {
+		t.Errorf("byte slice slice: got %q", got)
+	}
+	features := []string{"envoy.lb.does_not_support_overprovisioning", "envoy.lrs.supports_send_all_clusters"}
+	if got := deserializeProtobufToStringSlice(SerializeProtobufStringSlice(features)); !reflect.DeepEqual(got, features) {
+		t.Errorf("protobuf string slice: got %q", got)
+	}
+
+	headers := map[string]string{":path": "/get", "x-request-id": "req-1", "empty": ""}
+	if got := deserializeToStringMap(SerializeStringMap(headers)); !reflect.DeepEqual(got, headers) {
+		t.Errorf("string map: got %v", got)
+	}
+	fields := map[string][]byte{"concurrency": SerializeFloat64(2), "binaryPath": []byte("/usr/local/bin/envoy")}
+	if got := deserializeToByteMap(SerializeByteMap(fields)); !reflect.DeepEqual(got, fields) {
+		t.Errorf("byte map: got %v", got)
+	}
+}
+
+func TestSerializeEmpty(t *testing.T) {
+	if got := deserializeToStringSlice(SerializeStringSlice(nil)); len(got) != 0 {
+		t.Errorf("expected an empty string slice, got %q", got)
+	}
+	if got := deserializeToStringMap(SerializeStringMap(nil)); len(got) != 0 {
+		t.Errorf("expected an empty string map, got %v", got)
+	}
+}
+
+// Lengths of protobuf strings are varints, which take more than one byte from 128 bytes on
+// (e.g. long SPIFFE URIs or DNS SANs)
+func TestSerializeProtobufStringSliceLongStrings(t *testing.T) {
+	for _, tt := range []struct {
+		length int
+		want   []byte
+	}{
+		{127, []byte{0x0a, 0x7f}},
+		{128, []byte{0x0a, 0x80, 0x01}},
+		{20000, []byte{0x0a, 0xa0, 0x9c, 0x01}},
+	} {
+		bs := SerializeProtobufStringSlice([]string{strings.Repeat("a", tt.length)})
+		if got := bs[:len(tt.want)]; !bytes.Equal(got, tt.want) {
+			t.Errorf("expected the length %d encoded as varint % x, got % x", tt.length, tt.want, got)
+		}
+		if len(bs) != len(tt.want)+tt.length {
+			t.Errorf("expected %d bytes for a string of length %d, got %d", len(tt.want)+tt.length, tt.length, len(bs))
+		}
+	}
+}
@@ -3,15 +3,13 @@
 
 package properties
 
-import (
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
+import "time"
 
 // Get upstream connection remote address
 func GetUpstreamAddress() string {
 	upstreamAddress, err := getPropertyString([]string{"upstream", "address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.address: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.address: %v", err)
 		return ""
 	}
 	return upstreamAddress
@@ -21,7 +19,7 @@ func GetUpstreamAddress() string {
 func GetUpstreamPort() int {
 	upstreamPort, err := getPropertyUint64([]string{"upstream", "port"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.port: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.port: %v", err)
 		return 0
 	}
 	return int(upstreamPort)
@@ -31,7 +29,7 @@ func GetUpstreamPort() int {
 func GetUpstreamTlsVersion() string {
 	upstreamTlsVersion, err := getPropertyString([]string{"upstream", "tls_version"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.tls_version: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.tls_version: %v", err)
 		return ""
 	}
 	return upstreamTlsVersion
@@ -41,7 +39,7 @@ func GetUpstreamTlsVersion() string {
 func GetUpstreamSubjectLocalCertificate() string {
 	upstreamSubjectLocalCertificate, err := getPropertyString([]string{"upstream", "subject_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.subject_local_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.subject_local_certificate: %v", err)
 		return ""
 	}
 	return upstreamSubjectLocalCertificate
@@ -51,7 +49,7 @@ func GetUpstreamSubjectLocalCertificate() string {
 func GetUpstreamSubjectPeerCertificate() string {
 	upstreamSubjectPeerCertificate, err := getPropertyString([]string{"upstream", "subject_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.subject_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.subject_peer_certificate: %v", err)
 		return ""
 	}
 	return upstreamSubjectPeerCertificate
@@ -61,7 +59,7 @@ func GetUpstreamSubjectPeerCertificate() string {
 func GetUpstreamDnsSanLocalCertificate() string {
 	upstreamDnsSanLocalCertificate, err := getPropertyString([]string{"upstream", "dns_san_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.dns_san_local_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.dns_san_local_certificate: %v", err)
 		return ""
 	}
 	return upstreamDnsSanLocalCertificate
@@ -71,7 +69,7 @@ func GetUpstreamDnsSanLocalCertificate() string {
 func GetUpstreamDnsSanPeerCertificate() string {
 	upstreamDnsSanPeerCertificate, err := getPropertyString([]string{"upstream", "dns_san_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.dns_san_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.dns_san_peer_certificate: %v", err)
 		return ""
 	}
 	return upstreamDnsSanPeerCertificate
@@ -81,7 +79,7 @@ func GetUpstreamDnsSanPeerCertificate() string {
 func GetUpstreamUriSanLocalCertificate() string {
 	upstreamUriSanLocalCertificate, err := getPropertyString([]string{"upstream", "uri_san_local_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.uri_san_local_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.uri_san_local_certificate: %v", err)
 		return ""
 	}
 	return upstreamUriSanLocalCertificate
@@ -91,7 +89,7 @@ func GetUpstreamUriSanLocalCertificate() string {
 func GetUpstreamUriSanPeerCertificate() string {
 	upstreamUriSanPeerCertificate, err := getPropertyString([]string{"upstream", "uri_san_peer_certificate"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.uri_san_peer_certificate: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.uri_san_peer_certificate: %v", err)
 		return ""
 	}
 	return upstreamUriSanPeerCertificate
@@ -101,7 +99,7 @@ func GetUpstreamUriSanPeerCertificate() string {
 func GetUpstreamSha256PeerCertificateDigest() string {
 	upstreamSha256PeerCertificateDigest, err := getPropertyString([]string{"upstream", "sha256_peer_certificate_digest"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.sha256_peer_certificate_digest: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.sha256_peer_certificate_digest: %v", err)
 		return ""
 	}
 	return upstreamSha256PeerCertificateDigest
@@ -111,7 +109,7 @@ func GetUpstreamSha256PeerCertificateDigest() string {
 func GetUpstreamLocalAddress() string {
 	upstreamLocalAddress, err := getPropertyString([]string{"upstream", "local_address"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.local_address: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.local_address: %v", err)
 		return ""
 	}
 	return upstreamLocalAddress
@@ -121,8 +119,43 @@ func GetUpstreamLocalAddress() string {
 func GetUpstreamTransportFailureReason() string {
 	upstreamTransportFailureReason, err := getPropertyString([]string{"upstream", "transport_failure_reason"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading upstream attribute upstream.transport_failure_reason: %v", err)
+		logPropertyWarnf("failed reading upstream attribute upstream.transport_failure_reason: %v", err)
 		return ""
 	}
 	return upstreamTransportFailureReason
 }
+
+// Get all URI entries in the SAN field of the peer certificate in the upstream TLS connection
+//
+// Envoy only exposes the first URI entry as an attribute, so the result holds at most one element
+func GetUpstreamUriSanPeerCertificates() []string {
+	return sanEntries(GetUpstreamUriSanPeerCertificate())
+}
+
+// Get all DNS entries in the SAN field of the peer certificate in the upstream TLS connection
+//
+// Envoy only exposes the first DNS entry as an attribute, so the result holds at most one element
+func GetUpstreamDnsSanPeerCertificates() []string {
+	return sanEntries(GetUpstreamDnsSanPeerCertificate())
+}
+
+// Get the upstream request attempt count, which is 1 for the first attempt and is incremented
+// for every retry
+func GetUpstreamRequestAttemptCount() int {
+	upstreamRequestAttemptCount, err := getPropertyUint64([]string{"upstream", "request_attempt_count"})
+	if err != nil {
+		logPropertyWarnf("failed reading upstream attribute upstream.request_attempt_count: %v", err)
+		return 0
+	}
+	return int(upstreamRequestAttemptCount)
+}
+
+// Get the duration it took the upstream connection pool to provide a ready connection
+func GetUpstreamCxPoolReadyDuration() time.Duration {
+	upstreamCxPoolReadyDuration, err := getPropertyDuration([]string{"upstream", "cx_pool_ready_duration"})
+	if err != nil {
+		logPropertyWarnf("failed reading upstream attribute upstream.cx_pool_ready_duration: %v", err)
+		return 0
+	}
+	return upstreamCxPoolReadyDuration
+}
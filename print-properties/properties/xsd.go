@@ -2,9 +2,7 @@
 // https://www.envoyproxy.io/docs/envoy/latest/intro/arch_overview/advanced/attributes#configuration-attributes
 package properties
 
-import (
-	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm"
-)
+import "strings"
 
 // Get upstream cluster name
 //
@@ -12,7 +10,7 @@ import (
 func GetXdsClusterName() string {
 	xdsClusterName, err := getPropertyString([]string{"xds", "cluster_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading xsd configuration attribute xds.cluster_name: %v", err)
+		logPropertyWarnf("failed reading xsd configuration attribute xds.cluster_name: %v", err)
 		return ""
 	}
 	return xdsClusterName
@@ -28,7 +26,7 @@ func GetXdsClusterMetadata() IstioFilterMetadata {
 func GetXdsRouteName() string {
 	xdsRouteName, err := getPropertyString([]string{"xds", "route_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading xsd configuration attribute xds.route_name: %v", err)
+		logPropertyWarnf("failed reading xsd configuration attribute xds.route_name: %v", err)
 		return ""
 	}
 	return xdsRouteName
@@ -48,8 +46,78 @@ func GetXdsUpstreamHostMetadata() IstioFilterMetadata {
 func GetXdsListenerFilterChainName() string {
 	pluginName, err := getPropertyString([]string{"xds", "filter_chain_name"})
 	if err != nil {
-		proxywasm.LogWarnf("failed reading xsd configuration attribute xds.filter_chain_name: %v", err)
+		logPropertyWarnf("failed reading xsd configuration attribute xds.filter_chain_name: %v", err)
 		return ""
 	}
 	return pluginName
 }
+
+// Get listener metadata
+func GetXdsListenerMetadata() IstioFilterMetadata {
+	return getIstioFilterMetadata([]string{"xds", "listener_metadata", "filter_metadata", "istio"})
+}
+
+// Get listener direction, an enum value of the listener traffic direction (cfr GetListenerDirection())
+func GetXdsListenerDirection() TrafficDirection {
+	xdsListenerDirection, err := getPropertyUint64([]string{"xds", "listener_direction"})
+	if err != nil {
+		logPropertyWarnf("failed reading xsd configuration attribute xds.listener_direction: %v", err)
+		return Unspecified
+	}
+	return TrafficDirection(int(xdsListenerDirection))
+}
+
+// Get virtual host name
+//
+// Example value: "wasm.httpbin.org:80"
+func GetXdsVirtualHostName() string {
+	xdsVirtualHostName, err := getPropertyString([]string{"xds", "virtual_host_name"})
+	if err != nil {
+		logPropertyWarnf("failed reading xsd configuration attribute xds.virtual_host_name: %v", err)
+		return ""
+	}
+	return xdsVirtualHostName
+}
+
+// Get virtual host metadata
+func GetXdsVirtualHostMetadata() IstioFilterMetadata {
+	return getIstioFilterMetadata([]string{"xds", "virtual_host_metadata", "filter_metadata", "istio"})
+}
+
+// Identification of the Envoy node as reported to the management server
+//
+// https://www.envoyproxy.io/docs/envoy/latest/api-v3/config/core/v3/base.proto#envoy-v3-api-msg-config-core-v3-node
+type XdsNode struct {
+	Id               string
+	Cluster          string
+	Locality         Locality
+	UserAgentName    string
+	UserAgentVersion string
+}
+
+// Get the local node description (cfr GetNodeId(), GetNodeCluster() and GetNodeLocality())
+func GetXdsNode() XdsNode {
+	result := XdsNode{}
+
+	fields := []struct {
+		path  []string
+		value *string
+	}{
+		{[]string{"xds", "node", "id"}, &result.Id},
+		{[]string{"xds", "node", "cluster"}, &result.Cluster},
+		{[]string{"xds", "node", "locality", "region"}, &result.Locality.Region},
+		{[]string{"xds", "node", "locality", "zone"}, &result.Locality.Zone},
+		{[]string{"xds", "node", "locality", "subzone"}, &result.Locality.Subzone},
+		{[]string{"xds", "node", "user_agent_name"}, &result.UserAgentName},
+		{[]string{"xds", "node", "user_agent_version"}, &result.UserAgentVersion},
+	}
+	for _, field := range fields {
+		value, err := getPropertyString(field.path)
+		if err != nil {
+			logPropertyWarnf("failed reading xsd configuration attribute %v: %v", strings.Join(field.path, "."), err)
+		}
+		*field.value = value
+	}
+
+	return result
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"print-properties/properties"
+
+	"github.com/tidwall/gjson"
+)
+
+// defaultRedactedKeys are the header names redacted when no redaction is configured.
+var defaultRedactedKeys = []string{"authorization", "cookie", "set-cookie", "proxy-authorization"}
+
+// redactedValue replaces redacted properties when hashing is disabled.
+const redactedValue = "[REDACTED]"
+
+// redaction removes sensitive values from the collected properties before they are printed.
+// Keys are redacted inside every printed map (e.g. request headers or dynamic metadata),
+// properties are redacted as a whole (e.g. certificate subjects).
+type redaction struct {
+	keys       map[string]bool  // map keys to redact, lower-cased
+	keyRegex   []*regexp.Regexp // map keys matching any of these are redacted too
+	properties map[string]bool  // property names whose value gets redacted entirely
+	hash       bool             // replace redacted values by a hash instead of dropping them
+}
+
+// parseRedaction parses the "redaction" section of the plugin configuration.
+//
+// Example configuration:
+//
+//	"redaction": {
+//		"keys": ["authorization", "cookie", "set-cookie", "proxy-authorization", "x-api-key"],
+//		"keyRegex": ["^x-secret-"],
+//		"properties": ["GetDownstreamSubjectPeerCertificate"],
+//		"hash": true
+//	}
+func parseRedaction(jsonData gjson.Result) (redaction, error) {
+	r := redaction{
+		keys:       make(map[string]bool),
+		properties: make(map[string]bool),
+		hash:       jsonData.Get("hash").Bool(),
+	}
+
+	keys := defaultRedactedKeys
+	if jsonData.Get("keys").Exists() {
+		keys = make([]string, 0)
+		for _, key := range jsonData.Get("keys").Array() {
+			keys = append(keys, key.String())
+		}
+	}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+
+	for _, expr := range jsonData.Get("keyRegex").Array() {
+		regex, err := regexp.Compile("(?i)" + expr.String())
+		if err != nil {
+			return r, fmt.Errorf("invalid redaction regex %q: %v", expr.String(), err)
+		}
+		r.keyRegex = append(r.keyRegex, regex)
+	}
+
+	for _, property := range jsonData.Get("properties").Array() {
+		r.properties[property.String()] = true
+	}
+
+	return r, nil
+}
+
+// redactKey returns true if values stored under the given map key need to be redacted.
+func (r redaction) redactKey(key string) bool {
+	if r.keys[strings.ToLower(key)] {
+		return true
+	}
+	for _, regex := range r.keyRegex {
+		if regex.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// hashValue returns a short, stable fingerprint of a value, so equal secrets can still be
+// correlated across requests without being disclosed.
+func hashValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// queryProperties are the printed string properties holding a raw query string, pathProperties
+// the ones holding a request path that can end in a query string.
+var (
+	queryProperties = map[string]bool{"GetRequestQuery": true}
+	pathProperties  = map[string]bool{"GetRequestPath": true}
+)
+
+// apply redacts the properties of a collected group in place.
+func (r redaction) apply(g *groupDump) {
+	for i, p := range g.properties {
+		if r.properties[p.name] {
+			if r.hash {
+				g.properties[i].value = hashValue(fmt.Sprint(p.value))
+			} else {
+				g.properties[i].value = redactedValue
+			}
+			continue
+		}
+		if value, ok := p.value.(string); ok && queryProperties[p.name] {
+			g.properties[i].value = r.redactQuery(value)
+			continue
+		}
+		if value, ok := p.value.(string); ok && pathProperties[p.name] {
+			g.properties[i].value = r.redactPath(value)
+			continue
+		}
+		g.properties[i].value = r.redactValue(p.value)
+	}
+}
+
+// redactQuery redacts the parameters of a raw query string (e.g. "token=abc&page=1") by key,
+// keeping the order and encoding of the other parameters.
+func (r redaction) redactQuery(query string) string {
+	if query == "" {
+		return query
+	}
+	params := strings.Split(query, "&")
+	result := make([]string, 0, len(params))
+	for _, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		name := key
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			name = unescaped
+		}
+		if !r.redactKey(name) {
+			result = append(result, param)
+		} else if r.hash {
+			result = append(result, key+"="+hashValue(value))
+		}
+	}
+	return strings.Join(result, "&")
+}
+
+// redactPath redacts the query parameters of a request path (e.g. the :path header) by key.
+func (r redaction) redactPath(path string) string {
+	base, query, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+	return base + "?" + r.redactQuery(query)
+}
+
+// redactHeaderValue redacts the query parameters in the value of the :path pseudo header.
+func (r redaction) redactHeaderValue(name, value string) string {
+	if name == ":path" {
+		return r.redactPath(value)
+	}
+	return value
+}
+
+// redactValue returns a copy of the printed maps and header lists with the sensitive keys
+// dropped or hashed, other values are returned as is.
+func (r redaction) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]string:
+		result := make(map[string]string, len(v))
+		for key, val := range v {
+			if !r.redactKey(key) {
+				result[key] = r.redactHeaderValue(key, val)
+			} else if r.hash {
+				result[key] = hashValue(val)
+			}
+		}
+		return result
+	case map[string][]byte:
+		result := make(map[string][]byte, len(v))
+		for key, val := range v {
+			if !r.redactKey(key) {
+				result[key] = val
+			} else if r.hash {
+				result[key] = []byte(hashValue(string(val)))
+			}
+		}
+		return result
+	case properties.Headers:
+		result := make(properties.Headers, 0, len(v))
+		for _, header := range v {
+			if !r.redactKey(header[0]) {
+				result = append(result, [2]string{header[0], r.redactHeaderValue(header[0], header[1])})
+			} else if r.hash {
+				result = append(result, [2]string{header[0], hashValue(header[1])})
+			}
+		}
+		return result
+	case properties.QueryParams:
+		result := make(properties.QueryParams, len(v))
+		for key, values := range v {
+			if !r.redactKey(key) {
+				result[key] = values
+			} else if r.hash {
+				hashed := make([]string, 0, len(values))
+				for _, val := range values {
+					hashed = append(hashed, hashValue(val))
+				}
+				result[key] = hashed
+			}
+		}
+		return result
+	case properties.IstioFilterMetadata:
+		// the struct fields are printed from the "config" and "services" keys of the metadata
+		result := properties.IstioFilterMetadata{Config: r.redactField("config", v.Config)}
+		redactServices := r.redactKey("services")
+		if redactServices && !r.hash {
+			return result
+		}
+		for _, service := range v.Services {
+			if redactServices {
+				service = properties.IstioService{
+					Host:      hashValue(service.Host),
+					Name:      hashValue(service.Name),
+					Namespace: hashValue(service.Namespace),
+				}
+				result.Services = append(result.Services, service)
+				continue
+			}
+			result.Services = append(result.Services, properties.IstioService{
+				Host:      r.redactField("host", service.Host),
+				Name:      r.redactField("name", service.Name),
+				Namespace: r.redactField("namespace", service.Namespace),
+			})
+		}
+		return result
+	case properties.PeerMetadata:
+		result := v
+		result.Labels = r.redactValue(v.Labels).(map[string]string)
+		return result
+	default:
+		return value
+	}
+}
+
+// redactField returns the value of a struct field printed from the given metadata key, emptied
+// or hashed when the key needs to be redacted.
+func (r redaction) redactField(key, value string) string {
+	switch {
+	case !r.redactKey(key):
+		return value
+	case r.hash:
+		return hashValue(value)
+	default:
+		return ""
+	}
+}
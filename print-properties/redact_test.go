@@ -0,0 +1,195 @@
+//go:build proxytest
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"print-properties/properties"
+
+	"github.com/tidwall/gjson"
+)
+
+// mustParseRedaction parses the redaction configuration, failing the test on errors.
+func mustParseRedaction(t *testing.T, config string) redaction {
+	t.Helper()
+	r, err := parseRedaction(gjson.Parse(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRedactionDefaults(t *testing.T) {
+	r := mustParseRedaction(t, `{}`)
+	headers := map[string]string{
+		"authorization":       "Bearer token",
+		"cookie":              "session=1",
+		"set-cookie":          "session=1",
+		"proxy-authorization": "Basic dXNlcjpwYXNz",
+		"x-request-id":        "42",
+	}
+
+	got := r.redactValue(headers).(map[string]string)
+	if fmt.Sprint(got) != "map[x-request-id:42]" {
+		t.Errorf("expected the default sensitive headers to be dropped, got %v", got)
+	}
+	if len(headers) != 5 {
+		t.Error("expected the original map to be left untouched")
+	}
+}
+
+func TestRedactionHash(t *testing.T) {
+	r := mustParseRedaction(t, `{"keys": ["x-api-key"], "keyRegex": ["^x-secret-"], "hash": true}`)
+
+	got := r.redactValue(properties.Headers{
+		{"X-Api-Key", "key"},
+		{"X-Secret-Token", "token"},
+		{"authorization", "Bearer token"},
+	}).(properties.Headers)
+
+	want := properties.Headers{
+		{"X-Api-Key", hashValue("key")},
+		{"X-Secret-Token", hashValue("token")},
+		{"authorization", "Bearer token"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if hashValue("key") == hashValue("other") || len(hashValue("key")) != len("sha256:")+16 {
+		t.Errorf("unexpected hash %v", hashValue("key"))
+	}
+}
+
+func TestRedactionQueryParams(t *testing.T) {
+	r := mustParseRedaction(t, `{"keys": ["token"]}`)
+
+	got := r.redactValue(properties.QueryParams{"token": {"a", "b"}, "page": {"1"}}).(properties.QueryParams)
+	if fmt.Sprint(got) != "map[page:[1]]" {
+		t.Errorf("expected the token parameter to be dropped, got %v", got)
+	}
+}
+
+func TestRedactionByteMap(t *testing.T) {
+	r := mustParseRedaction(t, `{"keys": ["api_key"]}`)
+
+	got := r.redactValue(map[string][]byte{"api_key": []byte("k3y"), "canary": []byte("true")}).(map[string][]byte)
+	if fmt.Sprintf("%s", got) != "map[canary:true]" {
+		t.Errorf("expected the api_key entry to be dropped, got %s", got)
+	}
+}
+
+func TestRedactionIstioFilterMetadata(t *testing.T) {
+	metadata := properties.IstioFilterMetadata{
+		Config:   "/apis/networking.istio.io/v1alpha3/namespaces/default/destination-rule/tetrate-dr",
+		Services: []properties.IstioService{{Host: "tetrate.io", Name: "tetrate.io", Namespace: "default"}},
+	}
+
+	got := mustParseRedaction(t, `{"keys": ["config", "namespace"]}`).redactValue(metadata).(properties.IstioFilterMetadata)
+	want := properties.IstioFilterMetadata{Services: []properties.IstioService{{Host: "tetrate.io", Name: "tetrate.io"}}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	got = mustParseRedaction(t, `{"keys": ["services"]}`).redactValue(metadata).(properties.IstioFilterMetadata)
+	if got.Config != metadata.Config || len(got.Services) != 0 {
+		t.Errorf("expected the services to be dropped, got %+v", got)
+	}
+
+	got = mustParseRedaction(t, `{"keys": ["services"], "hash": true}`).redactValue(metadata).(properties.IstioFilterMetadata)
+	if len(got.Services) != 1 || got.Services[0].Host != hashValue("tetrate.io") {
+		t.Errorf("expected the services to be hashed, got %+v", got)
+	}
+	if metadata.Config == "" || metadata.Services[0].Namespace != "default" {
+		t.Error("expected the original metadata to be left untouched")
+	}
+}
+
+func TestRedactionPeerMetadata(t *testing.T) {
+	metadata := properties.PeerMetadata{
+		WorkloadName: "productpage-v1",
+		Labels:       map[string]string{"app": "productpage", "secret-label": "s3cr3t"},
+	}
+
+	got := mustParseRedaction(t, `{"keys": ["secret-label"]}`).redactValue(metadata).(properties.PeerMetadata)
+	if got.WorkloadName != "productpage-v1" || fmt.Sprint(got.Labels) != "map[app:productpage]" {
+		t.Errorf("expected only the secret label to be dropped, got %+v", got)
+	}
+	if len(metadata.Labels) != 2 {
+		t.Error("expected the original labels to be left untouched")
+	}
+}
+
+func TestRedactionQueryStrings(t *testing.T) {
+	r := mustParseRedaction(t, `{"keys": ["token", "api key"]}`)
+
+	g := &groupDump{name: "printRequestProperties"}
+	g.add("GetRequestQuery", "token=s3cr3t&page=1&api%20key=k3y")
+	g.add("GetRequestPath", "/get?page=1&token=s3cr3t")
+	g.add("GetRequestUrlPath", "/get")
+	g.add("GetRequestHeaders", map[string]string{":path": "/get?token=s3cr3t", "x-token": "kept"})
+	g.add("GetRequestHeaderList", properties.Headers{{":path", "/get?token=s3cr3t&page=1"}})
+	r.apply(g)
+
+	want := []string{"page=1", "/get?page=1", "/get", "map[:path:/get? x-token:kept]", "[[:path /get?page=1]]"}
+	for i, p := range g.properties {
+		if got := fmt.Sprint(p.value); got != want[i] {
+			t.Errorf("%v: expected %q, got %q", p.name, want[i], got)
+		}
+	}
+
+	hashed := mustParseRedaction(t, `{"keys": ["token"], "hash": true}`).redactPath("/get?token=s3cr3t")
+	if hashed != "/get?token="+hashValue("s3cr3t") {
+		t.Errorf("expected the token to be hashed, got %q", hashed)
+	}
+}
+
+func TestRedactionProperties(t *testing.T) {
+	g := &groupDump{name: "printConnectionProperties"}
+	g.add("GetDownstreamSubjectPeerCertificate", "CN=client")
+	g.add("GetDownstreamLocalPort", 443)
+
+	mustParseRedaction(t, `{"properties": ["GetDownstreamSubjectPeerCertificate"]}`).apply(g)
+	if g.properties[0].value != redactedValue || g.properties[1].value != 443 {
+		t.Errorf("expected only the certificate subject to be redacted, got %+v", g.properties)
+	}
+}
+
+func TestParseRedactionErrors(t *testing.T) {
+	if _, err := parseRedaction(gjson.Parse(`{"keyRegex": ["("]}`)); err == nil {
+		t.Error("expected an error for an invalid regex")
+	}
+}
+
+func TestRedactionInPrintedProperties(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"onHttpRequestHeaders": {"printRequestProperties": true},
+		"redaction": {"keys": ["authorization"], "properties": ["GetRequestQuery", "GetRequestQueryParams"]}
+	}`)
+	defer reset()
+
+	headers := map[string]string{":path": "/get", "authorization": "Bearer t0k3n", "x-request-id": "req-1"}
+	if err := host.SetProperty([]string{"request", "headers"}, properties.SerializeStringMap(headers)); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.SetProperty([]string{"request", "query"}, []byte("token=s3cr3t")); err != nil {
+		t.Fatal(err)
+	}
+	contextID := host.InitializeHttpContext()
+	host.CallOnRequestHeaders(contextID, [][2]string{{":path", "/get"}, {"authorization", "Bearer t0k3n"}}, true)
+
+	logs := host.GetInfoLogs()
+	if !containsLog(logs, ">> GetRequestHeaders: map[:path:/get x-request-id:req-1]") {
+		t.Errorf("expected the request headers to be printed without authorization, got %v", logs)
+	}
+	if !containsLog(logs, ">> GetRequestQuery: [REDACTED]") {
+		t.Errorf("expected the query to be redacted as a whole, got %v", logs)
+	}
+	for _, log := range logs {
+		if strings.Contains(log, "t0k3n") || strings.Contains(log, "s3cr3t") {
+			t.Errorf("expected sensitive values to be redacted, got %q", log)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tetratelabs/proxy-wasm-go-sdk/proxywasm/types"
+)
+
+func TestTcpContext(t *testing.T) {
+	host, reset := startTestPlugin(t, `{
+		"networkFilter": true,
+		"onNewConnection": {"printConnectionProperties": true, "printUpstreamProperties": true},
+		"onUpstreamData": {"printUpstreamProperties": true},
+		"onDownstreamClose": {"printConnectionProperties": true}
+	}`)
+	defer reset()
+
+	if err := host.SetProperty([]string{"source", "address"}, []byte("10.0.0.1:51234")); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.SetProperty([]string{"upstream", "address"}, []byte("10.0.0.2:5432")); err != nil {
+		t.Fatal(err)
+	}
+
+	contextID, action := host.InitializeConnection()
+	if action != types.ActionContinue {
+		t.Fatalf("expected the connection to continue, got %v", action)
+	}
+	host.CallOnDownstreamData(contextID, []byte("ping"))
+	host.CallOnUpstreamData(contextID, []byte("pong"))
+	host.CloseDownstreamConnection(contextID)
+
+	logs := host.GetInfoLogs()
+	for _, line := range []string{
+		"********** OnNewConnection **********",
+		">> GetDownstreamRemoteAddress: 10.0.0.1:51234",
+		">> skipping printUpstreamProperties: not available in OnNewConnection",
+		"********** OnUpstreamData **********",
+		">> GetUpstreamAddress: 10.0.0.2:5432",
+		"********** OnDownstreamClose **********",
+	} {
+		if !containsLog(logs, line) {
+			t.Errorf("expected log line %q, got %v", line, logs)
+		}
+	}
+	if containsLog(logs, "********** OnDownstreamData **********") {
+		t.Error("expected no output for an unconfigured event")
+	}
+}